@@ -0,0 +1,177 @@
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// expandHome replace a leading ~ with the home directory
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
+
+// within report whether p is root or under it
+func within(root, p string) bool {
+	return p == root || strings.HasPrefix(p, root+string(os.PathSeparator))
+}
+
+// resolvedInside report whether p is still under root once the symlinks of
+// its existing part are resolved, dangling links are never inside
+func resolvedInside(root, p string) bool {
+	existing, rest := p, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return false
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return false
+	}
+	return within(root, filepath.Join(real, rest))
+}
+
+// extractTar extract the archive r into dir, entries escaping dir (by name,
+// link target or through a symlinked parent) are refused
+func extractTar(r io.Reader, dir string) error {
+	dir = filepath.Clean(dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !within(dir, target) {
+			return fmt.Errorf("refusing to extract %s outside of %s", hdr.Name, dir)
+		}
+		// the archive comes from the container, it may have planted a
+		// symlink to write through
+		if target != dir && !resolvedInside(root, filepath.Dir(target)) {
+			return fmt.Errorf("refusing to extract %s through a symlink", hdr.Name)
+		}
+		mode := os.FileMode(hdr.Mode).Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if !resolvedInside(root, target) {
+				return fmt.Errorf("refusing to extract %s through a symlink", hdr.Name)
+			}
+			if err := os.MkdirAll(target, mode|0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			// replace a link instead of writing where it points
+			if fi, err := os.Lstat(target); err == nil && fi.Mode()&os.ModeSymlink != 0 {
+				os.Remove(target)
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			link := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(link) || !within(dir, filepath.Join(filepath.Dir(target), link)) {
+				return fmt.Errorf("refusing symlink %s -> %s outside of %s", hdr.Name, hdr.Linkname, dir)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(link, target); err != nil {
+				return err
+			}
+			// .. after another link can still lead out
+			if real, err := filepath.EvalSymlinks(target); err == nil && !within(root, real) {
+				os.Remove(target)
+				return fmt.Errorf("refusing symlink %s -> %s outside of %s", hdr.Name, hdr.Linkname, dir)
+			}
+		}
+		// devices, fifos & hard links are skipped
+	}
+}
+
+// writeTar write src (a file or directory) as a tar archive to w, entries
+// are named relative to the parent of src like `docker cp` does
+func writeTar(w io.Writer, src string) error {
+	src = filepath.Clean(src)
+	base := filepath.Dir(src)
+
+	tw := tar.NewWriter(w)
+	err := filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(p); err != nil {
+				return err
+			}
+		}
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(base, p)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name, link, body string
+	typ              byte
+}
+
+func makeTar(t *testing.T, entries ...tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Linkname: e.link, Typeflag: e.typ, Mode: 0o644, Size: int64(len(e.body))}
+		if e.typ == tar.TypeDir {
+			hdr.Mode = 0o755
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractTar(t *testing.T) {
+	dir := t.TempDir()
+	dst := filepath.Join(dir, "dst")
+	archive := makeTar(t,
+		tarEntry{name: "app/", typ: tar.TypeDir},
+		tarEntry{name: "app/main.go", typ: tar.TypeReg, body: "package main\n"},
+		tarEntry{name: "app/current", typ: tar.TypeSymlink, link: "main.go"},
+		tarEntry{name: "app/lib/up", typ: tar.TypeSymlink, link: "../main.go"},
+	)
+	if err := extractTar(archive, dst); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dst, "app", "current"))
+	if err != nil || string(b) != "package main\n" {
+		t.Errorf("app/current = %q, %v", b, err)
+	}
+	if link, _ := os.Readlink(filepath.Join(dst, "app", "lib", "up")); link != "../main.go" {
+		t.Errorf("app/lib/up -> %q", link)
+	}
+}
+
+func TestExtractTarSlip(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+	}{
+		{"dot dot name", []tarEntry{
+			{name: "../evil", typ: tar.TypeReg, body: "x"},
+		}},
+		{"absolute link", []tarEntry{
+			{name: "x", typ: tar.TypeSymlink, link: "OUTSIDE"},
+			{name: "x/.bashrc", typ: tar.TypeReg, body: "x"},
+		}},
+		{"relative link out", []tarEntry{
+			{name: "x", typ: tar.TypeSymlink, link: "../outside"},
+			{name: "x/.bashrc", typ: tar.TypeReg, body: "x"},
+		}},
+		{"dot dot through link", []tarEntry{
+			{name: "a/", typ: tar.TypeDir},
+			{name: "a/y", typ: tar.TypeSymlink, link: ".."},
+			{name: "x", typ: tar.TypeSymlink, link: "a/y/../outside"},
+			{name: "x/.bashrc", typ: tar.TypeReg, body: "x"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			outside := filepath.Join(dir, "outside")
+			if err := os.Mkdir(outside, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			entries := []tarEntry{}
+			for _, e := range tt.entries {
+				e.link = strings.Replace(e.link, "OUTSIDE", outside, 1)
+				entries = append(entries, e)
+			}
+
+			dst := filepath.Join(dir, "dst")
+			if err := extractTar(makeTar(t, entries...), dst); err == nil {
+				t.Error("malicious archive was extracted")
+			}
+			if got, _ := os.ReadDir(outside); len(got) != 0 {
+				t.Errorf("wrote %s outside of dst", got[0].Name())
+			}
+			if _, err := os.Stat(filepath.Join(dir, "evil")); err == nil {
+				t.Error("wrote evil outside of dst")
+			}
+		})
+	}
+}
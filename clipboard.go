@@ -0,0 +1,23 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// copyToClipboard copy s to the system clipboard via OSC52, which work over
+// ssh too, as long as the terminal support it
+func copyToClipboard(s string) error {
+	seq := osc52.New(s)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	// stdout belong to the renderer, the terminal is the same
+	_, err := seq.WriteTo(os.Stderr)
+	return err
+}
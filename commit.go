@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var (
+	// repository path component, the first one may be a registry host[:port]
+	repoComponentPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	registryPattern      = regexp.MustCompile(`^[a-zA-Z0-9.-]+(?::[0-9]+)?$`)
+	tagPattern           = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	exposePattern        = regexp.MustCompile(`^[0-9]+(?:-[0-9]+)?(?:/(?:tcp|udp|sctp))?$`)
+)
+
+// CommitMsg is the result of committing a container to an image
+type CommitMsg struct {
+	entry   int    // history entry
+	name    string // container
+	image   string // repository:tag
+	imageID string
+	err     error
+}
+
+// parseRepoTag split repository[:tag], tag default to latest
+func parseRepoTag(s string) (string, string, error) {
+	repo, tag := s, "latest"
+	if i := strings.LastIndex(s, ":"); i > strings.LastIndex(s, "/") {
+		repo, tag = s[:i], s[i+1:]
+	}
+	if !tagPattern.MatchString(tag) {
+		return "", "", fmt.Errorf("invalid tag %q", tag)
+	}
+	components := strings.Split(repo, "/")
+	for i, component := range components {
+		isRegistry := strings.ContainsAny(component, ".:") || component == "localhost"
+		if i == 0 && len(components) > 1 && isRegistry && registryPattern.MatchString(component) {
+			continue
+		}
+		if !repoComponentPattern.MatchString(component) {
+			return "", "", fmt.Errorf("invalid repository %q, use lowercase [a-z0-9._-]", repo)
+		}
+	}
+	return repo, tag, nil
+}
+
+func validateRepoTag(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("repository is required")
+	}
+	_, _, err := parseRepoTag(strings.TrimSpace(s))
+	return err
+}
+
+func validateCmd(s string) error {
+	if strings.HasPrefix(s, "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(s), &args); err != nil {
+			return fmt.Errorf("exec form must be a json array of strings")
+		}
+	}
+	return nil
+}
+
+func validateEnv(s string) error {
+	for _, kv := range strings.Fields(s) {
+		if k, _, ok := strings.Cut(kv, "="); !ok || k == "" {
+			return fmt.Errorf("%q is not KEY=VALUE", kv)
+		}
+	}
+	return nil
+}
+
+func validateExpose(s string) error {
+	for _, port := range strings.Fields(s) {
+		if !exposePattern.MatchString(port) {
+			return fmt.Errorf("%q is not port[/tcp|udp]", port)
+		}
+	}
+	return nil
+}
+
+func validateYesNo(s string) error {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "y", "yes", "n", "no":
+		return nil
+	}
+	return fmt.Errorf("yes or no")
+}
+
+// commitChanges build the Dockerfile instructions applied on commit
+func commitChanges(cmd, env, expose string) []string {
+	changes := []string{}
+	if cmd = strings.TrimSpace(cmd); cmd != "" {
+		changes = append(changes, "CMD "+cmd)
+	}
+	for _, kv := range strings.Fields(env) {
+		changes = append(changes, "ENV "+kv)
+	}
+	for _, port := range strings.Fields(expose) {
+		changes = append(changes, "EXPOSE "+port)
+	}
+	return changes
+}
+
+// commitContainer is like client.CommitContainer, which has no pause option
+func commitContainer(c *docker.Client, id, repo, tag, author, message string, changes []string, pause bool) (string, error) {
+	query := url.Values{}
+	query.Set("container", id)
+	query.Set("repo", repo)
+	query.Set("tag", tag)
+	query.Set("author", author)
+	query.Set("comment", message)
+	query.Set("pause", fmt.Sprint(pause))
+	for _, change := range changes {
+		query.Add("changes", change)
+	}
+	var out struct{ ID string }
+	err := apiRequest(c, "POST", "/commit?"+query.Encode(), nil, &out)
+	return out.ID, err
+}
+
+func openCommit(m model) (tea.Model, tea.Cmd) {
+	c := m.containers[m.cursor]
+	fields := []formField{
+		newFormField("Repository:tag", strings.ToLower(c.name)+":snapshot", "e.g. myapp:debug, registry:5000/team/app:v2", validateRepoTag),
+		newFormField("Author", "", "e.g. Jane Doe <jane@example.com>", nil),
+		newFormField("Message", "", "commit message", nil),
+		newFormField("CMD", "", `e.g. ["nginx", "-g", "daemon off;"], empty = unchanged`, validateCmd),
+		newFormField("ENV", "", "space separated KEY=VALUE", validateEnv),
+		newFormField("EXPOSE", "", "space separated port[/tcp|udp]", validateExpose),
+		newFormField("Pause", "yes", "pause the container during the commit, yes or no", validateYesNo),
+	}
+
+	id, name := c.id, c.name
+	m.openForm("Commit "+name, fields, func(m model, values []string) (model, tea.Cmd) {
+		image := strings.TrimSpace(values[0])
+		repo, tag, _ := parseRepoTag(image)
+		changes := commitChanges(values[3], values[4], values[5])
+		pause := strings.HasPrefix(strings.ToLower(strings.TrimSpace(values[6])), "y")
+		if m.dryRunMode {
+			args := []string{"commit", "--author", values[1], "--message", values[2], fmt.Sprintf("--pause=%v", pause)}
+			for _, change := range changes {
+				args = append(args, "--change", change)
+			}
+			m.dryRun("committing", []string{dockerCommand(append(args, name, repo+":"+tag)...)})
+			return m, nil
+		}
+
+		m.logs = fmt.Sprintf("📸 Committing %s to %s:%s\n", name, repo, tag)
+		entry := m.record("committing to "+repo+":"+tag, historyTarget{ID: id, Name: name})
+		return m, func() tea.Msg {
+			client, err := newClient()
+			if err != nil {
+				return CommitMsg{entry: entry, name: name, image: image, err: err}
+			}
+			imageID, err := commitContainer(client, id, repo, tag, values[1], values[2], changes, pause)
+			return CommitMsg{entry: entry, name: name, image: repo + ":" + tag, imageID: imageID, err: err}
+		}
+	})
+	return m, nil
+}
+
+func commitLog(msg CommitMsg) string {
+	if msg.err != nil {
+		return fmt.Sprintf("❌ Failed committing %s: %v\n", msg.name, msg.err)
+	}
+	return fmt.Sprintf("✅ Committed %s to %s (%s), see images page\n", msg.name, msg.image, shortID(msg.imageID))
+}
+
+// cursorToCommittedImage put the cursor on the last committed image
+func (m *model) cursorToCommittedImage() {
+	for i, img := range m.images {
+		if m.newImage != "" && img.id == m.newImage {
+			m.cursor = i
+			return
+		}
+	}
+}
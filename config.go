@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const configFileName = "config.json"
+
+type sortConfig struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// config is the user config, persisted as json under the user config dir
+// (e.g. ~/.config/killer-whale/config.json)
+type config struct {
+	Sort             map[string]sortConfig `json:"sort,omitempty"`               // map[pageName]sortConfig
+	Concurrency      int                   `json:"concurrency,omitempty"`        // max docker actions running at once
+	DiffIgnore       []string              `json:"diff_ignore,omitempty"`        // path prefixes hidden in diff view
+	TopPsArgs        string                `json:"top_ps_args,omitempty"`        // ps args of process list
+	PruneFilters     string                `json:"prune_filters,omitempty"`      // prefilled filters of prune wizard
+	Watch            []watchRule           `json:"watch,omitempty"`              // containers notified on exit, restart, ...
+	Notify           string                `json:"notify,omitempty"`             // bell|osc9|osc777|none
+	Pins             []string              `json:"pins,omitempty"`               // names of containers sorted first
+	Actions          []customAction        `json:"actions,omitempty"`            // user defined actions
+	ReadOnlyContexts []string              `json:"read_only_contexts,omitempty"` // docker contexts opened in read-only mode
+}
+
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "killer-whale"), nil
+}
+
+// loadConfig read the user config, a missing config file is not an error
+func loadConfig() (config, error) {
+	cfg := config{}
+	dir, err := configDir()
+	if err != nil {
+		return cfg, err
+	}
+	b, err := os.ReadFile(filepath.Join(dir, configFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	err = json.Unmarshal(b, &cfg)
+	return cfg, err
+}
+
+func saveConfig(cfg config) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, configFileName), b, 0644)
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// customAction is a user defined action from the config, Exec run in the
+// container, Shell on the host, both are templates of customTarget
+type customAction struct {
+	Name  string `json:"name"`
+	Key   string `json:"key,omitempty"`
+	Page  string `json:"page,omitempty"`  // containers (default), images or volumes
+	Label string `json:"label,omitempty"` // only containers with label "key" or "key=value"
+	Image string `json:"image,omitempty"` // only objects of image glob, e.g. "myorg/api*"
+	Exec  string `json:"exec,omitempty"`
+	Shell string `json:"shell,omitempty"`
+}
+
+// customTarget is the data of the command templates, e.g. {{.Name}}
+type customTarget struct {
+	ID     string
+	Name   string
+	Image  string
+	labels map[string]string
+}
+
+// outputView stream the output of a custom action
+type outputView struct {
+	title   string
+	lines   []string
+	running bool
+	run     int // id of the run, output of older runs is ignored
+	entry   int // history entry of the run
+	cancel  context.CancelFunc
+}
+
+// OutputMsg is a line of output of a custom action, or the result of a
+// target when finished is set, done when all ran
+type OutputMsg struct {
+	run      int
+	line     string
+	finished string // id of the target
+	err      error
+	done     bool
+	ch       chan OutputMsg // to wait for the next line
+}
+
+func (a customAction) page() string {
+	if a.Page == "" {
+		return pageNames[pageContainer]
+	}
+	return a.Page
+}
+
+func (a customAction) matches(t customTarget) bool {
+	if a.Image != "" {
+		if ok, _ := path.Match(a.Image, t.Image); !ok {
+			return false
+		}
+	}
+	if a.Label != "" && !(watchRule{Label: a.Label}).matches(t.Name, t.labels) {
+		return false
+	}
+	return true
+}
+
+// customBindings return the bindings of the custom actions of page, the
+// actions without key can only be run from the command palette
+func customBindings(actions []customAction, page int) []key.Binding {
+	bindings := []key.Binding{}
+	for _, a := range actions {
+		if a.page() != pageNames[page] {
+			continue
+		}
+		if a.Key == "" {
+			bindings = append(bindings, key.NewBinding(
+				key.WithKeys("custom:"+a.Name), // never typed
+				key.WithHelp(":", a.Name),
+			))
+			continue
+		}
+		bindings = append(bindings, key.NewBinding(key.WithKeys(a.Key), key.WithHelp(a.Key, a.Name)))
+	}
+	return bindings
+}
+
+// pageCustomActions return the custom actions of the current page, in the
+// same order as m.keys.Custom
+func (m model) pageCustomActions() []customAction {
+	actions := []customAction{}
+	for _, a := range m.config.Actions {
+		if a.page() == pageNames[m.page] {
+			actions = append(actions, a)
+		}
+	}
+	return actions
+}
+
+// customTargets return the selected objects, or the one under cursor
+func (m model) customTargets() []customTarget {
+	indexes := []int{}
+	for i := 0; i < getCurrentViewItemCount(m); i++ {
+		if _, ok := m.selected[i]; ok {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 && m.cursor >= 0 && m.cursor < getCurrentViewItemCount(m) {
+		indexes = append(indexes, m.cursor)
+	}
+
+	targets := []customTarget{}
+	for _, i := range indexes {
+		switch m.page {
+		case pageContainer:
+			c := m.containers[i]
+			targets = append(targets, customTarget{ID: c.id, Name: c.name, Image: c.ancestor, labels: c.labels})
+		case pageImage:
+			img := m.images[i]
+			targets = append(targets, customTarget{ID: img.id, Name: img.name, Image: img.name})
+		case pageVolume:
+			v := m.volumes[i]
+			targets = append(targets, customTarget{ID: v.name, Name: v.name})
+		}
+	}
+	return targets
+}
+
+// renderCommand render the command of t, every value printed is shell
+// quoted as names, images & labels come from the containers
+func renderCommand(text string, t customTarget) (string, error) {
+	tmpl, err := template.New("").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"quote": func(v interface{}) string { return shellQuote(fmt.Sprint(v)) }}).
+		Parse(text)
+	if err != nil {
+		return "", err
+	}
+	for _, tt := range tmpl.Templates() {
+		if tt.Tree != nil {
+			quoteActions(tt.Tree, tt.Tree.Root)
+		}
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, t); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// quoteActions pipe every printed action of node to quote, like
+// html/template escape them, {{.Name}} become {{.Name | quote}}
+func quoteActions(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			quoteActions(tree, child)
+		}
+	case *parse.IfNode:
+		quoteActions(tree, n.List)
+		quoteActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		quoteActions(tree, n.List)
+		quoteActions(tree, n.ElseList)
+	case *parse.WithNode:
+		quoteActions(tree, n.List)
+		quoteActions(tree, n.ElseList)
+	case *parse.ActionNode:
+		pipe := n.Pipe
+		if len(pipe.Decl) > 0 || len(pipe.Cmds) == 0 {
+			return // {{$x := ...}} print nothing
+		}
+		last := pipe.Cmds[len(pipe.Cmds)-1].Args
+		if id, ok := last[0].(*parse.IdentifierNode); ok && id.Ident == "quote" {
+			return // already quoted
+		}
+		quote := parse.NewIdentifier("quote").SetTree(tree).SetPos(n.Pos)
+		pipe.Cmds = append(pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{quote}})
+	}
+}
+
+// lineWriter send what is written line by line, without control characters,
+// until ctx is cancelled
+type lineWriter struct {
+	ctx context.Context
+	ch  chan<- OutputMsg
+	buf []byte
+}
+
+func (w *lineWriter) send(line string) {
+	select {
+	case w.ch <- OutputMsg{line: line}:
+	case <-w.ctx.Done():
+	}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		w.send(printable(string(w.buf[:i])))
+		w.buf = w.buf[i+1:]
+	}
+}
+
+func (w *lineWriter) flush() {
+	if len(w.buf) > 0 {
+		w.send(printable(string(w.buf)))
+		w.buf = nil
+	}
+}
+
+func runCustomAction(m model, a customAction) (tea.Model, tea.Cmd) {
+	if readOnly {
+		m.logs = "🔒 Custom actions are disabled in read-only mode\n"
+		return m, nil
+	}
+	if (a.Exec == "") == (a.Shell == "") {
+		m.logs = fmt.Sprintf("🚧 Action %q need either exec or shell\n", a.Name)
+		return m, nil
+	}
+	if a.Exec != "" && m.page != pageContainer {
+		m.logs = fmt.Sprintf("🚧 Action %q: exec only work on containers\n", a.Name)
+		return m, nil
+	}
+
+	targets, skipped := []customTarget{}, 0
+	for _, t := range m.customTargets() {
+		if a.matches(t) {
+			targets = append(targets, t)
+		} else {
+			skipped++
+		}
+	}
+	if len(targets) == 0 {
+		m.logs = fmt.Sprintf("🚧 Action %q doesn't apply to the selection\n", a.Name)
+		return m, nil
+	}
+	commands := []string{}
+	for _, t := range targets {
+		command, err := renderCommand(a.Exec+a.Shell, t)
+		if err != nil {
+			m.logs = fmt.Sprintf("🚧 Action %q: %v\n", a.Name, err)
+			return m, nil
+		}
+		commands = append(commands, command)
+	}
+
+	if m.dryRunMode {
+		for i, t := range targets {
+			if a.Exec != "" {
+				commands[i] = dockerCommand("exec", shortID(t.ID), "sh", "-c", commands[i])
+			}
+		}
+		m.dryRun("running "+a.Name+" on", commands)
+		return m, nil
+	}
+
+	m.logs = fmt.Sprintf("⚡ Running %s on %s object(s)\n", a.Name, itemCountStyle.Render(fmt.Sprint(len(targets))))
+	if skipped > 0 {
+		m.logs += fmt.Sprintf("🚧 Skipped %d not matching\n", skipped)
+	}
+
+	m.finishOutput()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.output = outputView{title: a.Name, running: true, run: m.output.run + 1, cancel: cancel}
+	history := []historyTarget{}
+	for _, t := range targets {
+		history = append(history, historyTarget{ID: t.ID, Name: t.Name})
+	}
+	m.output.entry = m.record("running "+a.Name, history...)
+	m.openDrill(drillOutput)
+
+	ch := make(chan OutputMsg, 256)
+	go func() {
+		defer close(ch)
+		w := &lineWriter{ctx: ctx, ch: ch}
+		for i, t := range targets {
+			if ctx.Err() != nil {
+				return
+			}
+			if len(targets) > 1 {
+				w.send(outputHeaderStyle.Render("── " + t.Name + " ──"))
+			}
+			w.send(outputHeaderStyle.Render("$ " + commands[i]))
+			exitCode, err := runCustomCommand(ctx, a, t, commands[i], w)
+			w.flush()
+			if ctx.Err() != nil {
+				return // left pending, recorded as cancelled
+			}
+			switch {
+			case err != nil:
+				w.send("🚧 " + err.Error())
+			case exitCode != 0:
+				err = fmt.Errorf("exit code %d", exitCode)
+				w.send(exitCodeStyle.Render(err.Error()))
+			}
+			select {
+			case ch <- OutputMsg{finished: t.ID, err: err}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return m, waitOutput(m.output.run, ch)
+}
+
+func runCustomCommand(ctx context.Context, a customAction, t customTarget, command string, w *lineWriter) (int, error) {
+	if a.Exec != "" {
+		client, err := newClient()
+		if err != nil {
+			return 0, err
+		}
+		return streamExecInContainer(ctx, client, t.ID, []string{"sh", "-c", command}, w)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdout, cmd.Stderr = w, w
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return exitErr.ExitCode(), nil
+	}
+	return 0, err
+}
+
+// waitOutput wait for the next line of output
+func waitOutput(run int, ch chan OutputMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return OutputMsg{run: run, done: true}
+		}
+		msg.run, msg.ch = run, ch
+		return msg
+	}
+}
+
+func handleOutput(m model, msg OutputMsg) (tea.Model, tea.Cmd) {
+	if msg.run != m.output.run {
+		return m, nil // from a cancelled run, stop listening
+	}
+	if msg.done {
+		m.finishOutput()
+		return m, nil
+	}
+	if msg.finished != "" {
+		m.recordOutcome(m.output.entry, msg.finished, outcomeOf(msg.err), msg.err)
+		return m, waitOutput(msg.run, msg.ch)
+	}
+	// follow the output unless scrolled up
+	follow := m.drillCursor >= len(m.output.lines)-1
+	m.output.lines = append(m.output.lines, msg.line)
+	if m.drill == drillOutput && follow {
+		m.moveDrillCursor(len(m.output.lines), len(m.output.lines))
+	}
+	return m, waitOutput(msg.run, msg.ch)
+}
+
+// finishOutput mark the run as done, cancelling it if still running, the
+// targets not done are recorded as cancelled
+func (m *model) finishOutput() {
+	if m.output.cancel != nil {
+		m.output.cancel()
+		m.output.cancel = nil
+	}
+	m.output.running = false
+	if m.output.entry > 0 && m.output.entry <= len(m.history) {
+		for _, t := range m.history[m.output.entry-1].Targets {
+			m.recordOutcome(m.output.entry, t.ID, outcomeCancelled, nil)
+		}
+	}
+}
+
+// customActionOf return the custom action bound to msg on the current page
+func (m model) customActionOf(msg tea.KeyMsg) (customAction, bool) {
+	actions := m.pageCustomActions()
+	for i, b := range m.keys.Custom {
+		if key.Matches(msg, b) && i < len(actions) {
+			return actions[i], true
+		}
+	}
+	return customAction{}, false
+}
+
+func buildOutputView(m model) (string, string) {
+	o := m.output
+	title := "Output of " + o.title
+	if o.running {
+		title += sortStyle.Render("  (running...)")
+	}
+	if len(o.lines) == 0 {
+		return title, "No output yet."
+	}
+	return title, renderRows(o.lines, m.drillCursor, m.drillOffset, drillHeight(m))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRenderCommand(t *testing.T) {
+	target := customTarget{ID: "abc123", Name: "web", Image: "nginx:1.25"}
+	evil := customTarget{ID: "abc123", Name: "web; rm -rf ~", Image: "x$(touch /tmp/pwned)"}
+	tests := []struct {
+		text   string
+		target customTarget
+		want   string
+	}{
+		{"docker logs {{.ID}}", target, "docker logs abc123"},
+		{"echo {{.Name}} {{.Image}}", target, "echo web nginx:1.25"},
+		{"echo {{.Name}}", evil, `echo 'web; rm -rf ~'`},
+		{"echo {{.Image}}", evil, `echo 'x$(touch /tmp/pwned)'`},
+		{"echo {{.Name | quote}}", evil, `echo 'web; rm -rf ~'`},
+		{`echo {{printf "%s-%s" .Name "x"}}`, evil, `echo 'web; rm -rf ~-x'`},
+		{`{{if eq .Name "web"}}echo {{.Image}}{{else}}true{{end}}`, target, "echo nginx:1.25"},
+		{`{{with .Image}}echo {{.}}{{end}}`, evil, `echo 'x$(touch /tmp/pwned)'`},
+		{`{{$n := .Name}}echo {{$n}}`, evil, `echo 'web; rm -rf ~'`},
+		{"echo {{len .Name}}", target, "echo 3"},
+		{"echo it's {{.Name}}", evil, `echo it's 'web; rm -rf ~'`},
+		{"echo {{.Name}}", customTarget{Name: "it's"}, `echo 'it'\''s'`},
+	}
+	for _, tt := range tests {
+		got, err := renderCommand(tt.text, tt.target)
+		if err != nil {
+			t.Errorf("%s: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := renderCommand("echo {{.Nope}}", target); err == nil {
+		t.Error("unknown field didn't fail")
+	}
+}
+
+func TestLineWriterStripControl(t *testing.T) {
+	ch := make(chan OutputMsg, 4)
+	w := &lineWriter{ctx: context.Background(), ch: ch}
+	w.Write([]byte("done\r\n\x1b]0;owned\x07tail"))
+	w.flush()
+	if got := (<-ch).line; got != "done" {
+		t.Errorf("line = %q, want done", got)
+	}
+	if got := (<-ch).line; got != "]0;ownedtail" {
+		t.Errorf("line = %q, want ]0;ownedtail", got)
+	}
+}
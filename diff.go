@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// noisy paths hidden by the diff filter unless set in config
+var defaultDiffIgnore = []string{"/tmp", "/var/cache"}
+
+var changeMarkers = map[docker.ChangeType]string{
+	docker.ChangeAdd:    "A",
+	docker.ChangeModify: "C",
+	docker.ChangeDelete: "D",
+}
+
+// implicitChange mark a directory that's not reported by docker but has
+// changed children
+const implicitChange docker.ChangeType = -1
+
+type diffNode struct {
+	path     string
+	kind     docker.ChangeType
+	children []*diffNode
+}
+
+// diffView is the state of the filesystem diff drill-down
+type diffView struct {
+	containerID string
+	name        string
+	changes     []docker.Change
+	err         error
+	loading     bool
+	filter      bool            // hide ignored paths
+	ignore      []string        // path prefixes hidden by filter
+	collapsed   map[string]bool // map[path]collapsed
+}
+
+type diffRow struct {
+	node  *diffNode
+	depth int
+}
+
+type DiffMsg struct {
+	containerID string
+	changes     []docker.Change
+	err         error
+}
+
+func fetchDiff(id string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return DiffMsg{containerID: id, err: err}
+		}
+		changes, err := client.ContainerChanges(id)
+		return DiffMsg{containerID: id, changes: changes, err: err}
+	}
+}
+
+func (d diffView) ignored(p string) bool {
+	if !d.filter {
+		return false
+	}
+	for _, prefix := range d.ignore {
+		if p == prefix || strings.HasPrefix(p, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// tree build the changed paths into a tree under "/"
+func (d diffView) tree() *diffNode {
+	root := &diffNode{path: "/", kind: implicitChange}
+	nodes := map[string]*diffNode{"/": root}
+
+	var getNode func(p string) *diffNode
+	getNode = func(p string) *diffNode {
+		if n, ok := nodes[p]; ok {
+			return n
+		}
+		n := &diffNode{path: p, kind: implicitChange}
+		nodes[p] = n
+		parent := getNode(path.Dir(p))
+		parent.children = append(parent.children, n)
+		return n
+	}
+
+	for _, c := range d.changes {
+		if d.ignored(c.Path) {
+			continue
+		}
+		getNode(c.Path).kind = c.Kind
+	}
+
+	for _, n := range nodes {
+		sort.Slice(n.children, func(i, j int) bool {
+			return n.children[i].path < n.children[j].path
+		})
+	}
+	return root
+}
+
+// rows flatten the tree, skipping children of collapsed directories
+func (d diffView) rows() []diffRow {
+	rows := []diffRow{}
+	var walk func(n *diffNode, depth int)
+	walk = func(n *diffNode, depth int) {
+		for _, child := range n.children {
+			rows = append(rows, diffRow{node: child, depth: depth})
+			if !d.collapsed[child.path] {
+				walk(child, depth+1)
+			}
+		}
+	}
+	walk(d.tree(), 0)
+	return rows
+}
+
+func openDiff(m model) (tea.Model, tea.Cmd) {
+	c := m.containers[m.cursor]
+	ignore := m.config.DiffIgnore
+	if ignore == nil {
+		ignore = defaultDiffIgnore
+	}
+	m.diff = diffView{
+		containerID: c.id,
+		name:        c.name,
+		loading:     true,
+		filter:      true,
+		ignore:      ignore,
+		collapsed:   make(map[string]bool),
+	}
+	m.openDrill(drillDiff)
+	return m, fetchDiff(c.id)
+}
+
+func handleDiffKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.diff.rows()
+	switch {
+	case key.Matches(msg, m.keys.Toggle): // expand/collapse directory
+		if m.drillCursor < len(rows) {
+			n := rows[m.drillCursor].node
+			if len(n.children) > 0 {
+				m.diff.collapsed[n.path] = !m.diff.collapsed[n.path]
+			}
+		}
+	case key.Matches(msg, m.keys.Filter): // hide/show noisy paths
+		m.diff.filter = !m.diff.filter
+		m.moveDrillCursor(0, len(m.diff.rows()))
+	}
+	return m, nil
+}
+
+func buildDiffView(m model) (string, string) {
+	d := m.diff
+	title := fmt.Sprintf("Diff of %s", d.name)
+	if d.filter {
+		title += sortStyle.Render(fmt.Sprintf("  (hiding %s)", strings.Join(d.ignore, ", ")))
+	}
+
+	switch {
+	case d.loading:
+		return title, "Loading..."
+	case d.err != nil:
+		return title, "🚧 " + d.err.Error()
+	}
+
+	rows := d.rows()
+	if len(rows) == 0 {
+		return title, "No changes."
+	}
+
+	lines := []string{}
+	for _, r := range rows {
+		n := r.node
+		branch := "  "
+		if len(n.children) > 0 {
+			branch = "▾ "
+			if d.collapsed[n.path] {
+				branch = "▸ "
+			}
+		}
+		marker := " "
+		if kind, ok := changeMarkers[n.kind]; ok {
+			marker = changeStyleMap[n.kind].Render(kind)
+		}
+		lines = append(lines, fmt.Sprintf("%s%s %s%s", strings.Repeat("  ", r.depth), marker, branch, path.Base(n.path)))
+	}
+	return title, renderRows(lines, m.drillCursor, m.drillOffset, drillHeight(m))
+}
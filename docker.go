@@ -1,23 +1,25 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
+	"strings"
 
 	docker "github.com/fsouza/go-dockerclient"
 )
 
-////////////////////////////////////////////
-// TODO: handle errors return from docker
-// client instead of assigning to _
-////////////////////////////////////////////
-
 // ---------------- Volume ----------------
-func removeVolume(c *docker.Client, id string) {
+func removeVolume(c *docker.Client, id string) error {
 	opts := docker.RemoveVolumeOptions{
 		Name:  id,
 		Force: true,
 	}
-	_ = c.RemoveVolumeWithOptions(opts)
+	return c.RemoveVolumeWithOptions(opts)
 }
 
 func listVolumes(c *docker.Client) []docker.Volume {
@@ -30,15 +32,12 @@ func listVolumes(c *docker.Client) []docker.Volume {
 }
 
 // ---------------- Image ----------------
-func removeImage(c *docker.Client, id string) {
+func removeImage(c *docker.Client, id string) error {
 	opts := docker.RemoveImageOptions{
 		Force: true,
 	}
 	// just tell em to remove the container that use this image first
-	err := c.RemoveImageExtended(id, opts)
-	if err != nil {
-		log.Fatal(err)
-	}
+	return c.RemoveImageExtended(id, opts)
 }
 
 func listImages(c *docker.Client, showAll bool) []docker.APIImages {
@@ -54,39 +53,39 @@ func listImages(c *docker.Client, showAll bool) []docker.APIImages {
 }
 
 // ---------------- Container ----------------
-func removeContainer(c *docker.Client, id string) {
+func removeContainer(c *docker.Client, id string) error {
 	opts := docker.RemoveContainerOptions{
 		ID:    id,
 		Force: true,
 	}
-	_ = c.RemoveContainer(opts)
+	return c.RemoveContainer(opts)
 }
 
-func restartContainer(c *docker.Client, id string) {
-	_ = c.RestartContainer(id, 5)
+func restartContainer(c *docker.Client, id string) error {
+	return c.RestartContainer(id, 5)
 }
 
-func unpauseContainer(c *docker.Client, id string) {
-	_ = c.UnpauseContainer(id)
+func unpauseContainer(c *docker.Client, id string) error {
+	return c.UnpauseContainer(id)
 }
 
-func pauseContainer(c *docker.Client, id string) {
-	_ = c.PauseContainer(id)
+func pauseContainer(c *docker.Client, id string) error {
+	return c.PauseContainer(id)
 }
 
-func killContainer(c *docker.Client, id string) {
+func killContainer(c *docker.Client, id string) error {
 	opts := docker.KillContainerOptions{
 		ID: id,
 	}
-	_ = c.KillContainer(opts)
+	return c.KillContainer(opts)
 }
 
-func startContainer(c *docker.Client, id string) {
-	_ = c.StartContainer(id, nil)
+func startContainer(c *docker.Client, id string) error {
+	return c.StartContainer(id, nil)
 }
 
-func stopContainer(c *docker.Client, id string) {
-	_ = c.StopContainer(id, 5)
+func stopContainer(c *docker.Client, id string) error {
+	return c.StopContainer(id, 5)
 }
 
 func listContainers(c *docker.Client, showAll bool) []docker.APIContainers {
@@ -96,3 +95,135 @@ func listContainers(c *docker.Client, showAll bool) []docker.APIContainers {
 	}
 	return containers
 }
+
+// execInContainer run cmd in a running container, wait for it to exit and
+// return its combined output & exit code
+func execInContainer(c *docker.Client, id string, cmd []string) (string, int, error) {
+	var out bytes.Buffer
+	exitCode, err := streamExecInContainer(context.Background(), c, id, cmd, &out)
+	return out.String(), exitCode, err
+}
+
+// streamExecInContainer run cmd in the container, writing its output to w
+// as it come, cancel ctx to stop following the output
+func streamExecInContainer(ctx context.Context, c *docker.Client, id string, cmd []string, w io.Writer) (int, error) {
+	if readOnly {
+		return 0, errReadOnly
+	}
+	exec, err := c.CreateExec(docker.CreateExecOptions{
+		Container:    id,
+		Cmd:          cmd,
+		AttachStdout: true,
+		AttachStderr: true,
+		Context:      ctx,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.StartExec(exec.ID, docker.StartExecOptions{
+		OutputStream: w,
+		ErrorStream:  w,
+		Context:      ctx,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	inspect, err := c.InspectExec(exec.ID)
+	if err != nil {
+		return 0, err
+	}
+	return inspect.ExitCode, nil
+}
+
+// downloadFromContainer stream a tar archive of path in the container, close
+// the reader or cancel ctx to stop the download early
+func downloadFromContainer(ctx context.Context, c *docker.Client, id, path string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		err := c.DownloadFromContainer(id, docker.DownloadFromContainerOptions{
+			Path:         path,
+			OutputStream: pw,
+			Context:      ctx,
+		})
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+func renameContainer(c *docker.Client, id, name string) error {
+	if readOnly {
+		return errReadOnly
+	}
+	return c.RenameContainer(docker.RenameContainerOptions{ID: id, Name: name})
+}
+
+// updateContainer live update resources & restart policy, only the keys in
+// changes are updated, go-dockerclient's UpdateContainerOptions can't leave
+// fields untouched nor set PidsLimit
+func updateContainer(c *docker.Client, id string, changes map[string]any) error {
+	return apiRequest(c, http.MethodPost, "/containers/"+id+"/update", changes, nil)
+}
+
+// uploadToContainer extract the tar archive r into dir in the container
+func uploadToContainer(c *docker.Client, id, dir string, r io.Reader) error {
+	if readOnly {
+		return errReadOnly
+	}
+	return c.UploadToContainer(id, docker.UploadToContainerOptions{
+		InputStream: r,
+		Path:        dir,
+	})
+}
+
+// apiRequest call an api endpoint go-dockerclient has no wrapper for, in is
+// sent as json body and the response decoded into out, unless they're nil
+func apiRequest(c *docker.Client, method, path string, in, out any) error {
+	if readOnly && method != http.MethodGet {
+		return errReadOnly
+	}
+	base := strings.TrimRight(c.Endpoint(), "/")
+	switch {
+	case strings.HasPrefix(base, "unix://"), strings.HasPrefix(base, "npipe://"):
+		base = "http://unix.sock" // host is ignored, the transport dial the socket
+	case strings.HasPrefix(base, "tcp://") && c.TLSConfig != nil:
+		base = "https://" + strings.TrimPrefix(base, "tcp://")
+	case strings.HasPrefix(base, "tcp://"):
+		base = "http://" + strings.TrimPrefix(base, "tcp://")
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, base+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct{ Message string }
+		body, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(body, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		return fmt.Errorf("%s %s: %s", method, path, apiErr.Message)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
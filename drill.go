@@ -0,0 +1,204 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// drill-down views replace the body with a full width view of the item
+// under cursor, esc go back to the list
+const (
+	drillNone int = iota
+	drillDiff
+	drillTop // shown in detail pane, next to the list
+	drillFiles
+	drillInspect
+	drillPrune
+	drillGraph
+	drillOutput
+)
+
+// drillKeyMap is the help of a drill-down view
+type drillKeyMap []key.Binding
+
+func (k drillKeyMap) ShortHelp() []key.Binding {
+	return k
+}
+
+func (k drillKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k}
+}
+
+// drillHeight return how many rows a drill-down view can show
+func drillHeight(m model) int {
+	// title, app border, header, status, log, help
+	height := m.height - 12
+	if height < minHeightPerView {
+		height = minHeightPerView
+	}
+	return height
+}
+
+// scrollToCursor return the offset that keep cursor within the view
+func scrollToCursor(cursor, offset, height int) int {
+	if cursor < offset {
+		return cursor
+	}
+	if cursor >= offset+height {
+		return cursor - height + 1
+	}
+	return offset
+}
+
+// renderRows render rows[offset:offset+height] with a cursor
+func renderRows(rows []string, cursor, offset, height int) string {
+	var s string
+	for i := offset; i < len(rows) && i < offset+height; i++ {
+		prefix := "  "
+		if i == cursor {
+			prefix = "❯ "
+		}
+		s += prefix + rows[i] + "\n"
+	}
+	return s
+}
+
+func (m *model) openDrill(kind int) {
+	m.drill = kind
+	m.drillCursor = 0
+	m.drillOffset = 0
+}
+
+func (m *model) closeDrill() {
+	m.drill = drillNone
+}
+
+// moveDrillCursor move cursor within rowCount rows, without wrapping around
+func (m *model) moveDrillCursor(delta, rowCount int) {
+	cursor := m.drillCursor + delta
+	if cursor >= rowCount {
+		cursor = rowCount - 1
+	}
+	if cursor < 0 {
+		cursor = 0
+	}
+	m.drillCursor = cursor
+	m.drillOffset = scrollToCursor(m.drillCursor, m.drillOffset, drillHeight(*m))
+}
+
+func drillRowCount(m model) int {
+	switch m.drill {
+	case drillDiff:
+		return len(m.diff.rows())
+	case drillTop:
+		return len(m.top.processes)
+	case drillFiles:
+		return m.files.rowCount()
+	case drillInspect:
+		return len(m.inspect.rows())
+	case drillPrune:
+		return len(m.prune.targets)
+	case drillGraph:
+		return len(m.graph.rows())
+	case drillOutput:
+		return len(m.output.lines)
+	}
+	return 0
+}
+
+func drillHelp(m model) drillKeyMap {
+	switch m.drill {
+	case drillDiff:
+		return drillKeyMap{m.keys.Up, m.keys.Down, m.keys.Toggle, m.keys.Filter, m.keys.Clear, m.keys.Quit}
+	case drillTop:
+		return drillKeyMap{m.keys.Up, m.keys.Down, m.keys.Sort, m.keys.SortOrder, m.keys.Signal, m.keys.Clear, m.keys.Quit}
+	case drillFiles:
+		if m.files.preview != nil {
+			return drillKeyMap{m.keys.Up, m.keys.Down, m.keys.Download, m.keys.Clear, m.keys.Quit}
+		}
+		return drillKeyMap{m.keys.Up, m.keys.Down, m.keys.Open, m.keys.Download, m.keys.Upload, m.keys.Clear, m.keys.Quit}
+	case drillInspect:
+		help := drillKeyMap{m.keys.Up, m.keys.Down, m.keys.Toggle, m.keys.Format, m.keys.Search, m.keys.NextMatch, m.keys.PrevMatch, m.keys.Copy}
+		if m.inspect.kind == inspectContainer {
+			help = append(help, m.keys.Jump)
+		}
+		return append(help, m.keys.Clear, m.keys.Quit)
+	case drillPrune:
+		return drillKeyMap{m.keys.Up, m.keys.Down, m.keys.Confirm, m.keys.Clear, m.keys.Quit}
+	case drillGraph:
+		return drillKeyMap{m.keys.Up, m.keys.Down, m.keys.GoTo, m.keys.Graph, m.keys.Clear, m.keys.Quit}
+	}
+	return drillKeyMap{m.keys.Clear, m.keys.Quit}
+}
+
+func handleDrillKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		return m, tea.Quit
+	case key.Matches(msg, m.keys.Clear):
+		if m.drill == drillFiles && m.files.preview != nil {
+			m.closePreview()
+			return m, nil
+		}
+		if m.drill == drillInspect && m.closeInspect() {
+			return m, nil
+		}
+		if m.drill == drillOutput {
+			m.finishOutput() // stop following
+		}
+		m.closeDrill()
+		return m, nil
+	case key.Matches(msg, m.keys.Copy) && m.dryRunLog != "": // dry run of a signal, upload, ...
+		return copyDryRunScript(&m)
+	case key.Matches(msg, m.keys.Up):
+		m.moveDrillCursor(-1, drillRowCount(m))
+		return m, nil
+	case key.Matches(msg, m.keys.Down):
+		m.moveDrillCursor(1, drillRowCount(m))
+		return m, nil
+	}
+
+	switch m.drill {
+	case drillDiff:
+		return handleDiffKeys(m, msg)
+	case drillTop:
+		return handleTopKeys(m, msg)
+	case drillFiles:
+		return handleFilesKeys(m, msg)
+	case drillInspect:
+		return handleInspectKeys(m, msg)
+	case drillPrune:
+		return handlePruneKeys(m, msg)
+	case drillGraph:
+		return handleGraphKeys(m, msg)
+	}
+	return m, nil
+}
+
+func buildDrillView(m model) string {
+	if m.drill == drillTop {
+		bodyL, _ := buildContainerView(m)
+		return lipgloss.JoinHorizontal(lipgloss.Left, bodyL, bodyRStyle.Render(buildTopView(m)))
+	}
+
+	var title, body string
+	switch m.drill {
+	case drillDiff:
+		title, body = buildDiffView(m)
+	case drillFiles:
+		title, body = buildFilesView(m)
+	case drillInspect:
+		title, body = buildInspectView(m)
+	case drillPrune:
+		title, body = buildPruneView(m)
+	case drillGraph:
+		title, body = buildGraphView(m)
+	case drillOutput:
+		title, body = buildOutputView(m)
+	}
+	body = strings.TrimSuffix(body, "\n")
+	return drillStyle.Render(lipgloss.JoinVertical(lipgloss.Left, drillTitleStyle.Render(title), body))
+}
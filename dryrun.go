@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dry-run log show at most this many commands, all are in the script
+const dryRunLogLines = 8
+
+// cliCommands is the docker cli equivalent of each executor op
+var cliCommands = map[string]string{
+	opStart:           "docker start %s",
+	opStop:            "docker stop -t 5 %s",
+	opRestart:         "docker restart -t 5 %s",
+	opKill:            "docker kill %s",
+	opPause:           "docker pause %s",
+	opUnpause:         "docker unpause %s",
+	opRemoveContainer: "docker rm -f %s",
+	opRemoveImage:     "docker rmi -f %s",
+	opRemoveVolume:    "docker volume rm -f %s",
+}
+
+// command render the docker cli command doing the same as the job
+func (j job) command() string {
+	target := j.id
+	if j.op != opRemoveVolume {
+		target = shortID(j.id)
+	}
+	command := fmt.Sprintf(cliCommands[j.op], target)
+	if j.name != "" && j.name != j.id {
+		command += "  # " + j.name
+	}
+	return command
+}
+
+// jobCommands return the commands of jobs, dropping their processes as
+// they won't run
+func (m *model) jobCommands(jobs []job) []string {
+	commands := []string{}
+	for _, j := range jobs {
+		delete(m.processes, j.id)
+		commands = append(commands, j.command())
+	}
+	return commands
+}
+
+// shellQuote quote s for sh, unless it's only made of safe characters
+func shellQuote(s string) string {
+	safe := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:=@%+,", r)
+	}
+	if s != "" && strings.IndexFunc(s, func(r rune) bool { return !safe(r) }) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// dockerCommand render a docker cli command, quoting its args
+func dockerCommand(args ...string) string {
+	for i, arg := range args {
+		args[i] = shellQuote(arg)
+	}
+	return "docker " + strings.Join(args, " ")
+}
+
+func buildCacheCommand(id string) string {
+	return "docker builder prune -f --filter id=" + id
+}
+
+// dryRun render commands into the log instead of running them, y copy
+// them as a shell script
+func (m *model) dryRun(action string, commands []string) {
+	m.dryRunScript = fmt.Sprintf("#!/bin/sh\n# killer-whale dry run: %s %d object(s)\n", action, len(commands))
+	if endpoint != "" {
+		m.dryRunScript += "export DOCKER_HOST=" + endpoint + "\n"
+	}
+	m.dryRunScript += strings.Join(commands, "\n") + "\n"
+
+	log := fmt.Sprintf("🧪 Dry run, %s %s object(s), nothing was done:\n",
+		action, itemCountStyle.Render(fmt.Sprint(len(commands))))
+	for i, command := range commands {
+		if i == dryRunLogLines {
+			log += fmt.Sprintf("… %d more\n", len(commands)-i)
+			break
+		}
+		log += dryRunCommandStyle.Render(command) + "\n"
+	}
+	m.dryRunLog = log + "press y to copy as a shell script\n"
+}
+
+func toggleDryRun(m *model) (tea.Model, tea.Cmd) {
+	m.dryRunMode = !m.dryRunMode
+	m.dryRunLog = ""
+	if m.dryRunMode {
+		m.logs = "🧪 Dry run on, actions only show their docker commands\n"
+	} else {
+		m.logs = "🧪 Dry run off\n"
+	}
+	return m, nil
+}
+
+func copyDryRunScript(m *model) (tea.Model, tea.Cmd) {
+	if err := copyToClipboard(m.dryRunScript); err != nil {
+		m.logs = "❌ Failed to copy: " + err.Error() + "\n"
+	} else {
+		m.logs = fmt.Sprintf("📋 Copied dry run script (%d lines)\n", strings.Count(m.dryRunScript, "\n"))
+	}
+	m.dryRunLog = ""
+	return m, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const defaultConcurrency = 4
+
+var errCancelled = errors.New("cancelled")
+
+// backend is the set of mutating docker calls run by the executor,
+// swap it with a fake to test the executor without a docker daemon
+type backend interface {
+	StartContainer(id string) error
+	StopContainer(id string) error
+	RestartContainer(id string) error
+	KillContainer(id string) error
+	PauseContainer(id string) error
+	UnpauseContainer(id string) error
+	RemoveContainer(id string) error
+	RemoveImage(id string) error
+	RemoveVolume(name string) error
+}
+
+type dockerBackend struct {
+	client *docker.Client
+}
+
+func (b dockerBackend) StartContainer(id string) error   { return startContainer(b.client, id) }
+func (b dockerBackend) StopContainer(id string) error    { return stopContainer(b.client, id) }
+func (b dockerBackend) RestartContainer(id string) error { return restartContainer(b.client, id) }
+func (b dockerBackend) KillContainer(id string) error    { return killContainer(b.client, id) }
+func (b dockerBackend) PauseContainer(id string) error   { return pauseContainer(b.client, id) }
+func (b dockerBackend) UnpauseContainer(id string) error { return unpauseContainer(b.client, id) }
+func (b dockerBackend) RemoveContainer(id string) error  { return removeContainer(b.client, id) }
+func (b dockerBackend) RemoveImage(id string) error      { return removeImage(b.client, id) }
+func (b dockerBackend) RemoveVolume(name string) error   { return removeVolume(b.client, name) }
+
+const (
+	opStart           = "start"
+	opStop            = "stop"
+	opRestart         = "restart"
+	opKill            = "kill"
+	opPause           = "pause"
+	opUnpause         = "unpause"
+	opRemoveContainer = "rm"
+	opRemoveImage     = "rmi"
+	opRemoveVolume    = "volume rm"
+)
+
+// job is a single docker call on a single target
+type job struct {
+	id   string
+	name string
+	op   string
+}
+
+func (j job) run(b backend) error {
+	switch j.op {
+	case opStart:
+		return b.StartContainer(j.id)
+	case opStop:
+		return b.StopContainer(j.id)
+	case opRestart:
+		return b.RestartContainer(j.id)
+	case opKill:
+		return b.KillContainer(j.id)
+	case opPause:
+		return b.PauseContainer(j.id)
+	case opUnpause:
+		return b.UnpauseContainer(j.id)
+	case opRemoveContainer:
+		return b.RemoveContainer(j.id)
+	case opRemoveImage:
+		return b.RemoveImage(j.id)
+	case opRemoveVolume:
+		return b.RemoveVolume(j.id)
+	}
+	return fmt.Errorf("unknown operation %q", j.op)
+}
+
+// JobDoneMsg is sent every time a job of a batch finish (or get cancelled)
+type JobDoneMsg struct {
+	batch int
+	label string // e.g. "stopping"
+	id    string
+	err   error
+	done  int // finished jobs in this batch so far
+	total int
+}
+
+// executor run jobs off the ui goroutine, at most concurrency jobs at the
+// same time across all batches
+type executor struct {
+	backend backend
+	sem     chan struct{}
+	updates chan JobDoneMsg
+	pending int32
+
+	mu        sync.Mutex
+	nextBatch int
+	cancel    chan struct{} // closed to drop every queued job
+}
+
+func newExecutor(b backend, concurrency int) *executor {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &executor{
+		backend: b,
+		sem:     make(chan struct{}, concurrency),
+		updates: make(chan JobDoneMsg, 64),
+		cancel:  make(chan struct{}),
+	}
+}
+
+// submit queue a batch of jobs, jobs are started in order
+func (e *executor) submit(label string, jobs []job) {
+	if len(jobs) == 0 {
+		return
+	}
+	e.mu.Lock()
+	e.nextBatch++
+	batch, cancel := e.nextBatch, e.cancel
+	e.mu.Unlock()
+	atomic.AddInt32(&e.pending, int32(len(jobs)))
+
+	// count & send together, so the ui see done in order and the last
+	// message of the batch is the one with done == total
+	var reportMu sync.Mutex
+	done := 0
+	report := func(j job, err error) {
+		reportMu.Lock()
+		defer reportMu.Unlock()
+		atomic.AddInt32(&e.pending, -1)
+		done++
+		e.updates <- JobDoneMsg{
+			batch: batch,
+			label: label,
+			id:    j.id,
+			err:   err,
+			done:  done,
+			total: len(jobs),
+		}
+	}
+
+	go func() {
+		for _, j := range jobs {
+			// check cancel first, select pick randomly when both are ready
+			select {
+			case <-cancel:
+				report(j, errCancelled)
+				continue
+			default:
+			}
+
+			select {
+			case <-cancel:
+				report(j, errCancelled)
+				continue
+			case e.sem <- struct{}{}:
+			}
+
+			go func(j job) {
+				err := j.run(e.backend)
+				<-e.sem
+				report(j, err)
+			}(j)
+		}
+	}()
+}
+
+// cancelQueued drop every job that hasn't started yet, running jobs are
+// left to finish, return the number of unfinished jobs
+func (e *executor) cancelQueued() int {
+	e.mu.Lock()
+	close(e.cancel)
+	e.cancel = make(chan struct{})
+	e.mu.Unlock()
+	return int(atomic.LoadInt32(&e.pending))
+}
+
+// listen wait for the next finished job
+func (e *executor) listen() tea.Cmd {
+	return func() tea.Msg {
+		return <-e.updates
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBackend record the calls, jobs block until release is closed (if
+// set) and fail when their id is in fail
+type fakeBackend struct {
+	mu      sync.Mutex
+	calls   []string
+	running int
+	maxRun  int
+	started chan string
+	release chan struct{}
+	fail    map[string]error
+}
+
+func (b *fakeBackend) do(op, id string) error {
+	b.mu.Lock()
+	b.calls = append(b.calls, op+" "+id)
+	b.running++
+	if b.running > b.maxRun {
+		b.maxRun = b.running
+	}
+	b.mu.Unlock()
+	if b.started != nil {
+		b.started <- id
+	}
+	if b.release != nil {
+		<-b.release
+	}
+	b.mu.Lock()
+	b.running--
+	b.mu.Unlock()
+	return b.fail[id]
+}
+
+func (b *fakeBackend) StartContainer(id string) error   { return b.do(opStart, id) }
+func (b *fakeBackend) StopContainer(id string) error    { return b.do(opStop, id) }
+func (b *fakeBackend) RestartContainer(id string) error { return b.do(opRestart, id) }
+func (b *fakeBackend) KillContainer(id string) error    { return b.do(opKill, id) }
+func (b *fakeBackend) PauseContainer(id string) error   { return b.do(opPause, id) }
+func (b *fakeBackend) UnpauseContainer(id string) error { return b.do(opUnpause, id) }
+func (b *fakeBackend) RemoveContainer(id string) error  { return b.do(opRemoveContainer, id) }
+func (b *fakeBackend) RemoveImage(id string) error      { return b.do(opRemoveImage, id) }
+func (b *fakeBackend) RemoveVolume(name string) error   { return b.do(opRemoveVolume, name) }
+
+func jobsOf(op string, n int) []job {
+	jobs := []job{}
+	for i := 0; i < n; i++ {
+		jobs = append(jobs, job{id: fmt.Sprint("c", i), op: op})
+	}
+	return jobs
+}
+
+// collect wait for n messages of the executor
+func collect(t *testing.T, e *executor, n int) []JobDoneMsg {
+	t.Helper()
+	msgs := []JobDoneMsg{}
+	for len(msgs) < n {
+		select {
+		case msg := <-e.updates:
+			msgs = append(msgs, msg)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d of %d messages", len(msgs), n)
+		}
+	}
+	return msgs
+}
+
+func TestExecutorConcurrencyLimit(t *testing.T) {
+	b := &fakeBackend{started: make(chan string, 10), release: make(chan struct{})}
+	e := newExecutor(b, 2)
+	e.submit("stopping", jobsOf(opStop, 6))
+
+	<-b.started
+	<-b.started
+	select {
+	case id := <-b.started:
+		t.Fatalf("%s started over the limit", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(b.release)
+
+	msgs := collect(t, e, 6)
+	if b.maxRun != 2 {
+		t.Errorf("max running = %d, want 2", b.maxRun)
+	}
+	if len(b.calls) != 6 {
+		t.Errorf("calls = %v, want 6", b.calls)
+	}
+	for i, msg := range msgs {
+		if msg.done != i+1 || msg.total != 6 || msg.err != nil {
+			t.Errorf("message %d = %+v, want done %d of 6", i, msg, i+1)
+		}
+	}
+}
+
+func TestExecutorDoneInOrder(t *testing.T) {
+	b := &fakeBackend{}
+	e := newExecutor(b, 8)
+	for run := 0; run < 20; run++ {
+		e.submit("starting", jobsOf(opStart, 8))
+		for i, msg := range collect(t, e, 8) {
+			if msg.done != i+1 {
+				t.Fatalf("run %d: message %d has done %d", run, i, msg.done)
+			}
+		}
+	}
+}
+
+func TestExecutorCancelQueued(t *testing.T) {
+	b := &fakeBackend{started: make(chan string, 10), release: make(chan struct{})}
+	e := newExecutor(b, 1)
+	e.submit("removing", jobsOf(opRemoveContainer, 4))
+
+	<-b.started // c0 is running, the rest is queued
+	if pending := e.cancelQueued(); pending != 4 {
+		t.Errorf("pending = %d, want 4", pending)
+	}
+	close(b.release)
+
+	msgs := collect(t, e, 4)
+	cancelled := 0
+	for _, msg := range msgs {
+		switch {
+		case msg.id == "c0" && msg.err != nil:
+			t.Errorf("running job was cancelled: %v", msg.err)
+		case msg.id != "c0" && !errors.Is(msg.err, errCancelled):
+			t.Errorf("%s: err = %v, want cancelled", msg.id, msg.err)
+		case msg.id != "c0":
+			cancelled++
+		}
+	}
+	if cancelled != 3 || len(b.calls) != 1 {
+		t.Errorf("cancelled %d, calls %v, want 3 cancelled & 1 call", cancelled, b.calls)
+	}
+	if msgs[3].done != 4 {
+		t.Errorf("last message done = %d, want 4", msgs[3].done)
+	}
+
+	// a new batch isn't affected by the old cancel
+	e.submit("removing", jobsOf(opRemoveContainer, 2))
+	for _, msg := range collect(t, e, 2) {
+		if msg.err != nil {
+			t.Errorf("%s: err = %v after cancel", msg.id, msg.err)
+		}
+	}
+}
+
+func TestExecutorJobErrors(t *testing.T) {
+	errBusy := errors.New("volume is in use")
+	b := &fakeBackend{fail: map[string]error{"c1": errBusy}}
+	e := newExecutor(b, 2)
+	e.submit("removing", jobsOf(opRemoveVolume, 3))
+
+	errs := map[string]error{}
+	for _, msg := range collect(t, e, 3) {
+		errs[msg.id] = msg.err
+	}
+	if errs["c0"] != nil || errs["c2"] != nil || !errors.Is(errs["c1"], errBusy) {
+		t.Errorf("errors = %v, want only c1 to fail", errs)
+	}
+
+	e.submit("doing", []job{{id: "c0", op: "bogus"}})
+	if msg := collect(t, e, 1)[0]; msg.err == nil {
+		t.Error("unknown operation didn't fail")
+	}
+}
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// the archive api, used when the container can't exec, send the whole
+	// tree of a directory, stop reading after this many entries so huge
+	// directories stay responsive
+	fileScanLimit = 5000
+	// largest file viewed inline
+	filePreviewSize = 64 * 1024
+)
+
+type fileEntry struct {
+	name string
+	mode os.FileMode
+	size int64
+	link string // symlink target
+}
+
+func (e fileEntry) isDir() bool {
+	return e.mode.IsDir()
+}
+
+func (e fileEntry) isLink() bool {
+	return e.mode&os.ModeSymlink != 0
+}
+
+// filePreview is a small text file viewed inline
+type filePreview struct {
+	path    string
+	lines   []string
+	err     error
+	loading bool
+	cursor  int // cursor of the listing, restored on close
+}
+
+// filesView is the state of the file browser of a container
+type filesView struct {
+	containerID string
+	name        string
+	dir         string
+	entries     []fileEntry
+	truncated   bool
+	err         error
+	loading     bool
+	preview     *filePreview
+}
+
+type FilesMsg struct {
+	containerID string
+	dir         string
+	entries     []fileEntry
+	truncated   bool
+	err         error
+}
+
+type FilePreviewMsg struct {
+	containerID string
+	path        string
+	content     string
+	err         error
+}
+
+type TransferMsg struct {
+	entry       int // history entry of uploads
+	containerID string
+	upload      bool
+	src, dst    string
+	err         error
+}
+
+// listDirScript print "<raw mode in hex> <size> ./<name>" for each direct
+// child of $1, then "-> ./<name>" & the target of each symlink
+const listDirScript = `cd -- "$1" || exit 1
+find . -mindepth 1 -maxdepth 1 -exec stat -c '%f %s %n' {} + 2>/dev/null || exit 1
+find . -mindepth 1 -maxdepth 1 -type l | while read -r l; do
+	printf -- '-> %s\n' "$l"
+	readlink -- "$l"
+done`
+
+// listFiles list the direct children of dir, with find & stat in the
+// container, or from its tar archive if it can't exec (stopped, no shell)
+func listFiles(id, dir string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return FilesMsg{containerID: id, dir: dir, err: err}
+		}
+		var truncated bool
+		entries, err := listDirExec(client, id, dir)
+		if err != nil {
+			entries, truncated, err = listDirTar(client, id, dir)
+		}
+		if err != nil {
+			return FilesMsg{containerID: id, dir: dir, err: err}
+		}
+
+		// directories first
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].isDir() != entries[j].isDir() {
+				return entries[i].isDir()
+			}
+			return entries[i].name < entries[j].name
+		})
+		return FilesMsg{containerID: id, dir: dir, entries: entries, truncated: truncated}
+	}
+}
+
+func listDirExec(c *docker.Client, id, dir string) ([]fileEntry, error) {
+	out, code, err := execInContainer(c, id, []string{"sh", "-c", listDirScript, "sh", dir})
+	if err != nil {
+		return nil, err
+	}
+	if code != 0 {
+		return nil, fmt.Errorf("listing %s exited with code %d", dir, code)
+	}
+	return parseListDir(out), nil
+}
+
+// parseListDir parse the output of listDirScript, lines that can't be
+// parsed (names with a newline) are skipped
+func parseListDir(out string) []fileEntry {
+	entries := []fileEntry{}
+	index := map[string]int{}
+	lines := strings.Split(out, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "-> ./") {
+			if j, ok := index[strings.TrimPrefix(line, "-> ./")]; ok && i+1 < len(lines) {
+				entries[j].link = lines[i+1]
+				i++
+			}
+			continue
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 || !strings.HasPrefix(fields[2], "./") {
+			continue
+		}
+		mode, err := strconv.ParseInt(fields[0], 16, 64)
+		if err != nil {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		name := strings.TrimPrefix(fields[2], "./")
+		index[name] = len(entries)
+		entries = append(entries, fileEntry{
+			name: name,
+			// tar use the same mode bits as stat
+			mode: (&tar.Header{Mode: mode}).FileInfo().Mode(),
+			size: size,
+		})
+	}
+	return entries
+}
+
+// listDirTar list the direct children of dir from its tar archive, which
+// hold the whole tree, entries past fileScanLimit are missing
+func listDirTar(c *docker.Client, id, dir string) ([]fileEntry, bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := downloadFromContainer(ctx, c, id, dir)
+	defer r.Close()
+
+	var prefix string
+	entries := []fileEntry{}
+	tr := tar.NewReader(r)
+	for n := 0; ; n++ {
+		if n == fileScanLimit {
+			return entries, true, nil
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		// first entry is the directory itself
+		name := strings.TrimSuffix(hdr.Name, "/")
+		if n == 0 {
+			prefix = name
+			continue
+		}
+		if prefix != "" {
+			if !strings.HasPrefix(name, prefix+"/") {
+				continue
+			}
+			name = name[len(prefix)+1:]
+		}
+		if name == "" || strings.Contains(name, "/") {
+			continue
+		}
+		entries = append(entries, fileEntry{
+			name: name,
+			mode: hdr.FileInfo().Mode(),
+			size: hdr.Size,
+			link: hdr.Linkname,
+		})
+	}
+}
+
+func fetchFilePreview(id, p string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return FilePreviewMsg{containerID: id, path: p, err: err}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := downloadFromContainer(ctx, client, id, p)
+		defer r.Close()
+
+		tr := tar.NewReader(r)
+		hdr, err := tr.Next()
+		if err != nil {
+			return FilePreviewMsg{containerID: id, path: p, err: err}
+		}
+		if hdr.Size > filePreviewSize {
+			err = fmt.Errorf("too large to view (%s), download it instead", convertSizeToHumanRedable(hdr.Size))
+			return FilePreviewMsg{containerID: id, path: p, err: err}
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return FilePreviewMsg{containerID: id, path: p, err: err}
+		}
+		if bytes.IndexByte(data, 0) >= 0 {
+			err = fmt.Errorf("binary file, download it instead")
+			return FilePreviewMsg{containerID: id, path: p, err: err}
+		}
+		return FilePreviewMsg{containerID: id, path: p, content: string(data)}
+	}
+}
+
+// downloadFiles copy src out of the container, as a tar archive if dst end
+// with .tar, otherwise extracted into the dst directory
+func downloadFiles(id, src, dst string) tea.Cmd {
+	return func() tea.Msg {
+		msg := TransferMsg{containerID: id, src: src, dst: dst}
+		client, err := newClient()
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := downloadFromContainer(ctx, client, id, src)
+		defer r.Close()
+
+		dst = expandHome(dst)
+		if !strings.HasSuffix(dst, ".tar") {
+			msg.err = extractTar(r, dst)
+			return msg
+		}
+
+		f, err := os.Create(dst)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		_, err = io.Copy(f, r)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		msg.err = err
+		return msg
+	}
+}
+
+// uploadFiles copy the host file or directory src into dir in the container
+func uploadFiles(entry int, id, src, dir string) tea.Cmd {
+	return func() tea.Msg {
+		msg := TransferMsg{entry: entry, containerID: id, upload: true, src: src, dst: dir}
+		client, err := newClient()
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		src = expandHome(src)
+		if _, err := os.Lstat(src); err != nil {
+			msg.err = err
+			return msg
+		}
+
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(writeTar(pw, src))
+		}()
+		msg.err = uploadToContainer(client, id, dir, pr)
+		pr.Close() // unblock the writer if upload failed early
+		return msg
+	}
+}
+
+// rows is the listing with a parent entry on top, unless at root
+func (f filesView) rows() []fileEntry {
+	if f.dir == "/" {
+		return f.entries
+	}
+	return append([]fileEntry{{name: "..", mode: os.ModeDir}}, f.entries...)
+}
+
+func (f filesView) rowCount() int {
+	if f.preview != nil {
+		return len(f.preview.lines)
+	}
+	return len(f.rows())
+}
+
+// target return the path under cursor, the current directory if on parent
+func (f filesView) target(cursor int) string {
+	if f.preview != nil {
+		return f.preview.path
+	}
+	rows := f.rows()
+	if cursor >= len(rows) || rows[cursor].name == ".." {
+		return f.dir
+	}
+	return path.Join(f.dir, rows[cursor].name)
+}
+
+func (m *model) changeDir(dir string) tea.Cmd {
+	m.files.dir = dir
+	m.files.loading = true
+	m.files.entries = nil
+	m.files.err = nil
+	m.drillCursor = 0
+	m.drillOffset = 0
+	return listFiles(m.files.containerID, dir)
+}
+
+func (m *model) closePreview() {
+	cursor := m.files.preview.cursor
+	m.files.preview = nil
+	m.drillCursor = 0
+	m.drillOffset = 0
+	m.moveDrillCursor(cursor, m.files.rowCount())
+}
+
+func openFiles(m model) (tea.Model, tea.Cmd) {
+	c := m.containers[m.cursor]
+	m.files = filesView{
+		containerID: c.id,
+		name:        c.name,
+	}
+	m.openDrill(drillFiles)
+	cmd := m.changeDir("/")
+	return m, cmd
+}
+
+func handleFilesKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.files
+	switch {
+	case key.Matches(msg, m.keys.Open): // enter directory or view file
+		rows := f.rows()
+		if f.preview != nil || m.drillCursor >= len(rows) {
+			return m, nil
+		}
+		e := rows[m.drillCursor]
+		switch {
+		case e.name == "..":
+			cmd := m.changeDir(path.Dir(f.dir))
+			return m, cmd
+		case e.isDir():
+			cmd := m.changeDir(path.Join(f.dir, e.name))
+			return m, cmd
+		case e.isLink():
+			target := e.link
+			if !path.IsAbs(target) {
+				target = path.Join(f.dir, target)
+			}
+			cmd := m.changeDir(target)
+			return m, cmd
+		}
+		p := path.Join(f.dir, e.name)
+		m.files.preview = &filePreview{path: p, loading: true, cursor: m.drillCursor}
+		m.drillCursor = 0
+		m.drillOffset = 0
+		return m, fetchFilePreview(f.containerID, p)
+
+	case key.Matches(msg, m.keys.Download): // copy to host
+		src, id := f.target(m.drillCursor), f.containerID
+		label := fmt.Sprintf("download %s to (dir, or .tar file)", src)
+		m.openPrompt(label, ".", func(m model, dst string) (model, tea.Cmd) {
+			if dst = strings.TrimSpace(dst); dst == "" {
+				return m, nil
+			}
+			m.logs = fmt.Sprintf("📥 Downloading %s to %s...\n", src, dst)
+			return m, downloadFiles(id, src, dst)
+		})
+
+	case key.Matches(msg, m.keys.Upload): // copy from host
+		if f.preview != nil {
+			return m, nil
+		}
+		dir, id, name := f.dir, f.containerID, f.name
+		m.openPrompt(fmt.Sprintf("upload to %s from", dir), "", func(m model, src string) (model, tea.Cmd) {
+			if src = strings.TrimSpace(src); src == "" {
+				return m, nil
+			}
+			if m.dryRunMode {
+				m.dryRun("uploading", []string{dockerCommand("cp", src, shortID(id)+":"+dir)})
+				return m, nil
+			}
+			m.logs = fmt.Sprintf("📤 Uploading %s to %s...\n", src, dir)
+			entry := m.record("uploading "+src+" to "+dir, historyTarget{ID: id, Name: name})
+			return m, uploadFiles(entry, id, src, dir)
+		})
+	}
+	return m, nil
+}
+
+func buildFilesView(m model) (string, string) {
+	f := m.files
+	if p := f.preview; p != nil {
+		switch {
+		case p.loading:
+			return p.path, "Loading..."
+		case p.err != nil:
+			return p.path, "🚧 " + p.err.Error()
+		case len(p.lines) == 0:
+			return p.path, "Empty file."
+		}
+		return p.path, renderRows(p.lines, m.drillCursor, m.drillOffset, drillHeight(m))
+	}
+
+	title := fmt.Sprintf("Files of %s: %s", f.name, f.dir)
+	if f.truncated {
+		title += sortStyle.Render(fmt.Sprintf("  (incomplete, stopped after %d files of the tree, entries are missing)", fileScanLimit))
+	}
+	switch {
+	case f.loading:
+		return title, "Loading..."
+	case f.err != nil:
+		return title, "🚧 " + f.err.Error()
+	}
+
+	rows := f.rows()
+	if len(rows) == 0 {
+		return title, "Empty directory."
+	}
+
+	lines := []string{}
+	for _, e := range rows {
+		name := printable(e.name)
+		size := convertSizeToHumanRedable(e.size)
+		switch {
+		case e.isDir():
+			name = fileDirStyle.Render(name + "/")
+			size = ""
+		case e.isLink():
+			name = fileLinkStyle.Render(name) + " -> " + printable(e.link)
+		}
+		mode := e.mode.String()
+		if e.name == ".." {
+			mode = ""
+		}
+		lines = append(lines, fmt.Sprintf("%-11s %10s  %s", mode, size, name))
+	}
+	return title, renderRows(lines, m.drillCursor, m.drillOffset, drillHeight(m))
+}
+
+// previewLines split content into lines, tabs expanded so widths stay sane
+// & control characters dropped
+func previewLines(content string) []string {
+	content = strings.ReplaceAll(strings.TrimSuffix(content, "\n"), "\t", "    ")
+	if content == "" {
+		return nil
+	}
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = printable(line)
+	}
+	return lines
+}
+
+// transferLog describe the result of a download or upload
+func transferLog(msg TransferMsg) string {
+	verb := "Downloaded"
+	if msg.upload {
+		verb = "Uploaded"
+	}
+	if msg.err != nil {
+		return fmt.Sprintf("❌ Failed copying %s to %s: %v\n", msg.src, msg.dst, msg.err)
+	}
+	dst := msg.dst
+	if !msg.upload {
+		if abs, err := filepath.Abs(expandHome(dst)); err == nil {
+			dst = abs
+		}
+	}
+	return fmt.Sprintf("✅ %s %s to %s\n", verb, msg.src, dst)
+}
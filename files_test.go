@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseListDir(t *testing.T) {
+	out := "41ed 4096 ./etc\n" +
+		"81a4 12 ./my file.txt\n" +
+		"a1ff 7 ./lib\n" +
+		"81ed 0 ./.hidden\n" +
+		"garbage\n" +
+		"-> ./lib\n" +
+		"usr/lib\n"
+	want := map[string]struct {
+		mode os.FileMode
+		size int64
+		link string
+	}{
+		"etc":         {os.ModeDir | 0o755, 4096, ""},
+		"my file.txt": {0o644, 12, ""},
+		"lib":         {os.ModeSymlink | 0o777, 7, "usr/lib"},
+		".hidden":     {0o755, 0, ""},
+	}
+
+	entries := parseListDir(out)
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %d", entries, len(want))
+	}
+	for _, e := range entries {
+		w, ok := want[e.name]
+		if !ok || e.mode != w.mode || e.size != w.size || e.link != w.link {
+			t.Errorf("%q = %v %d %q, want %v %d %q", e.name, e.mode, e.size, e.link, w.mode, w.size, w.link)
+		}
+	}
+}
+
+// TestListDirScript run the script with the local shell, like in a container
+func TestListDirScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh")
+	}
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "sub"), 0o755)
+	os.WriteFile(filepath.Join(dir, "sub", "deep.txt"), []byte("deep"), 0o644)
+	os.WriteFile(filepath.Join(dir, "a b.txt"), []byte("hello"), 0o600)
+	os.WriteFile(filepath.Join(dir, ".env"), nil, 0o644)
+	os.Symlink("sub/deep.txt", filepath.Join(dir, "link"))
+
+	out, err := exec.Command("sh", "-c", listDirScript, "sh", dir).Output()
+	if err != nil {
+		t.Skipf("no find or stat: %v", err)
+	}
+	got := map[string]fileEntry{}
+	for _, e := range parseListDir(string(out)) {
+		got[e.name] = e
+	}
+	if len(got) != 4 {
+		t.Fatalf("entries = %+v, want 4 direct children", got)
+	}
+	if !got["sub"].isDir() || got["a b.txt"].size != 5 || got["a b.txt"].mode != 0o600 {
+		t.Errorf("entries = %+v", got)
+	}
+	if l := got["link"]; !l.isLink() || l.link != "sub/deep.txt" {
+		t.Errorf("link = %+v", l)
+	}
+	if _, ok := got[".env"]; !ok {
+		t.Error("hidden file missing")
+	}
+
+	if err := exec.Command("sh", "-c", listDirScript, "sh", filepath.Join(dir, "nope")).Run(); err == nil {
+		t.Error("missing directory didn't fail")
+	}
+}
+
+func TestPreviewLines(t *testing.T) {
+	got := previewLines("a\tb\r\n\x1b]52;c;cm0gLXJmIH4=\x07x\n\n")
+	want := []string{"a    b", "]52;c;cm0gLXJmIH4=x", ""}
+	if len(got) != len(want) {
+		t.Fatalf("lines = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
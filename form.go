@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// formField is a text field, validate return an error for invalid values
+type formField struct {
+	label    string
+	hint     string
+	input    textinput.Model
+	validate func(value string) error
+	err      error
+}
+
+// form is a multi field text input shown in place of the body, enter
+// submit the values to onSubmit once all are valid, esc cancel
+type form struct {
+	title    string
+	fields   []formField
+	focus    int
+	onSubmit func(m model, values []string) (model, tea.Cmd)
+}
+
+func newFormField(label, value, hint string, validate func(string) error) formField {
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetValue(value)
+	input.CursorEnd()
+	return formField{label: label, hint: hint, input: input, validate: validate}
+}
+
+func (m *model) openForm(title string, fields []formField, onSubmit func(m model, values []string) (model, tea.Cmd)) {
+	f := &form{title: title, fields: fields, onSubmit: onSubmit}
+	f.focusField(0)
+	m.form = f
+}
+
+func (f *form) focusField(i int) {
+	f.fields[f.focus].input.Blur()
+	f.focus = (i + len(f.fields)) % len(f.fields)
+	f.fields[f.focus].input.Focus()
+}
+
+// validate check every field, focus the first invalid one
+func (f *form) validate() bool {
+	valid := true
+	for i := len(f.fields) - 1; i >= 0; i-- {
+		field := &f.fields[i]
+		field.err = nil
+		if field.validate != nil {
+			field.err = field.validate(field.input.Value())
+		}
+		if field.err != nil {
+			valid = false
+			f.focusField(i)
+		}
+	}
+	return valid
+}
+
+func handleFormKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	f := m.form
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.form = nil
+		m.logs = "🚫 Cancelled\n"
+		return m, nil
+	case key.Matches(msg, m.keys.NextField):
+		f.focusField(f.focus + 1)
+		return m, nil
+	case key.Matches(msg, m.keys.PrevField):
+		f.focusField(f.focus - 1)
+		return m, nil
+	case msg.Type == tea.KeyEnter:
+		if !f.validate() {
+			return m, nil
+		}
+		values := []string{}
+		for _, field := range f.fields {
+			values = append(values, field.input.Value())
+		}
+		m.form = nil
+		return f.onSubmit(m, values)
+	}
+
+	var cmd tea.Cmd
+	field := &f.fields[f.focus]
+	field.input, cmd = field.input.Update(msg)
+	field.err = nil
+	return m, cmd
+}
+
+func formHelp(m model) drillKeyMap {
+	submit := key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "submit"))
+	cancel := key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel"))
+	return drillKeyMap{m.keys.NextField, m.keys.PrevField, submit, cancel}
+}
+
+func buildFormView(m model) string {
+	f := m.form
+	labelWidth := 0
+	for _, field := range f.fields {
+		if w := runewidth.StringWidth(field.label); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	body := ""
+	for i, field := range f.fields {
+		cursor := "  "
+		if i == f.focus {
+			cursor = "❯ "
+		}
+		label := field.label + runewidth.FillRight("", labelWidth-runewidth.StringWidth(field.label))
+		body += fmt.Sprintf("%s%s : %s\n", cursor, label, field.input.View())
+		switch {
+		case field.err != nil:
+			body += "  " + runewidth.FillRight("", labelWidth+3) + formErrorStyle.Render("✗ "+field.err.Error()) + "\n"
+		case i == f.focus && field.hint != "":
+			body += "  " + runewidth.FillRight("", labelWidth+3) + sortStyle.Render(field.hint) + "\n"
+		}
+	}
+	return drillStyle.Render(lipgloss.JoinVertical(lipgloss.Left, drillTitleStyle.Render(f.title), body))
+}
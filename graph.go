@@ -0,0 +1,405 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	composeServiceLabel   = "com.docker.compose.service"
+	composeDependsOnLabel = "com.docker.compose.depends_on" // e.g. db:service_started:false,cache:...
+)
+
+// graphNode is an object related to the center of the graph, kind & id are
+// empty for group nodes such as "volumes"
+type graphNode struct {
+	label    string
+	kind     string // inspectContainer, inspectImage, ...
+	id       string
+	children []*graphNode
+}
+
+func (n *graphNode) add(children ...*graphNode) {
+	n.children = append(n.children, children...)
+}
+
+// addGroup add a group node, only if it has any children
+func (n *graphNode) addGroup(label string, children []*graphNode) {
+	if len(children) > 0 {
+		n.add(&graphNode{label: label, children: children})
+	}
+}
+
+// graphView is the state of the relationship graph drill-down
+type graphView struct {
+	kind    string
+	id      string
+	name    string
+	root    *graphNode
+	err     error
+	loading bool
+}
+
+type graphRow struct {
+	node   *graphNode
+	prefix string // tree branches
+}
+
+type GraphMsg struct {
+	kind string
+	id   string
+	root *graphNode
+	err  error
+}
+
+func apiContainerName(c docker.APIContainers) string {
+	if len(c.Names) == 0 {
+		return shortID(c.ID)
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+func containerNode(c docker.APIContainers, detail string) *graphNode {
+	label := fmt.Sprintf("%s (%s)", apiContainerName(c), normalizeState(c.State))
+	if detail != "" {
+		label += " " + detail
+	}
+	return &graphNode{label: label, kind: inspectContainer, id: c.ID}
+}
+
+// composeDependencies parse the depends_on label into service names
+func composeDependencies(labels map[string]string) []string {
+	services := []string{}
+	for _, dep := range strings.Split(labels[composeDependsOnLabel], ",") {
+		if service, _, _ := strings.Cut(dep, ":"); service != "" {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+// composeContainers return the containers of service in project
+func composeContainers(containers []docker.APIContainers, project, service string) []docker.APIContainers {
+	found := []docker.APIContainers{}
+	for _, c := range containers {
+		if c.Labels[composeProjectLabel] == project && c.Labels[composeServiceLabel] == service {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+func fetchGraph(kind, id, name string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return GraphMsg{kind: kind, id: id, err: err}
+		}
+		containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+		if err != nil {
+			return GraphMsg{kind: kind, id: id, err: err}
+		}
+
+		var root *graphNode
+		switch kind {
+		case inspectContainer:
+			root, err = containerGraph(client, containers, id)
+		case inspectImage:
+			root, err = imageGraph(client, containers, id)
+		case inspectVolume:
+			root = volumeGraph(containers, name)
+		case graphPods:
+			root, err = podsGraph(client, containers)
+		}
+		return GraphMsg{kind: kind, id: id, root: root, err: err}
+	}
+}
+
+func containerGraph(client *docker.Client, containers []docker.APIContainers, id string) (*graphNode, error) {
+	ctr, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	byID := map[string]docker.APIContainers{}
+	byName := map[string]docker.APIContainers{}
+	for _, c := range containers {
+		byID[c.ID] = c
+		byName[apiContainerName(c)] = c
+	}
+
+	hostConfig := ctr.HostConfig
+	if hostConfig == nil {
+		hostConfig = &docker.HostConfig{}
+	}
+	config := ctr.Config // podman may leave it out
+	if config == nil {
+		config = &docker.Config{}
+	}
+
+	root := &graphNode{
+		label: fmt.Sprintf("%s (%s)", strings.TrimPrefix(ctr.Name, "/"), ctr.State.Status),
+		kind:  inspectContainer,
+		id:    ctr.ID,
+	}
+	root.add(&graphNode{label: "image " + config.Image, kind: inspectImage, id: ctr.Image})
+
+	// volumes & bind mounts
+	mounts := []*graphNode{}
+	for _, mnt := range ctr.Mounts {
+		if mnt.Name == "" {
+			mounts = append(mounts, &graphNode{label: fmt.Sprintf("bind %s → %s", mnt.Source, mnt.Destination)})
+			continue
+		}
+		mounts = append(mounts, &graphNode{
+			label: fmt.Sprintf("%s → %s", mnt.Name, mnt.Destination),
+			kind:  inspectVolume,
+			id:    mnt.Name,
+		})
+	}
+	root.addGroup("volumes", mounts)
+
+	// networks, with the other containers attached to each
+	networkNames := []string{}
+	if ctr.NetworkSettings != nil {
+		for name := range ctr.NetworkSettings.Networks {
+			networkNames = append(networkNames, name)
+		}
+	}
+	sort.Strings(networkNames)
+	networks := []*graphNode{}
+	for _, name := range networkNames {
+		network := &graphNode{label: name, kind: inspectNetwork, id: name}
+		for _, c := range containers {
+			if _, ok := c.Networks.Networks[name]; ok && c.ID != ctr.ID {
+				network.add(containerNode(c, ""))
+			}
+		}
+		networks = append(networks, network)
+	}
+	// network_mode: container:<id>
+	if mode := hostConfig.NetworkMode; strings.HasPrefix(mode, "container:") {
+		ref := strings.TrimPrefix(mode, "container:")
+		node := &graphNode{label: "network of " + ref}
+		if c, ok := byID[ref]; ok {
+			node = containerNode(c, "(shared network stack)")
+		} else if c, ok := byName[ref]; ok {
+			node = containerNode(c, "(shared network stack)")
+		}
+		networks = append(networks, node)
+	}
+	root.addGroup("networks", networks)
+
+	// legacy links, e.g. /db:/web/db
+	links := []*graphNode{}
+	for _, link := range hostConfig.Links {
+		target, alias, _ := strings.Cut(link, ":")
+		target = strings.TrimPrefix(target, "/")
+		alias = alias[strings.LastIndex(alias, "/")+1:]
+		if c, ok := byName[target]; ok {
+			links = append(links, containerNode(c, "as "+alias))
+		} else {
+			links = append(links, &graphNode{label: target + " as " + alias})
+		}
+	}
+	root.addGroup("links", links)
+
+	// compose depends_on, both ways
+	project, service := config.Labels[composeProjectLabel], config.Labels[composeServiceLabel]
+	dependsOn, neededBy := []*graphNode{}, []*graphNode{}
+	if project != "" {
+		for _, dep := range composeDependencies(config.Labels) {
+			found := composeContainers(containers, project, dep)
+			if len(found) == 0 {
+				dependsOn = append(dependsOn, &graphNode{label: dep + " (no container)"})
+			}
+			for _, c := range found {
+				dependsOn = append(dependsOn, containerNode(c, ""))
+			}
+		}
+		for _, c := range containers {
+			if c.Labels[composeProjectLabel] != project {
+				continue
+			}
+			for _, dep := range composeDependencies(c.Labels) {
+				if dep == service {
+					neededBy = append(neededBy, containerNode(c, ""))
+					break
+				}
+			}
+		}
+	}
+	root.addGroup("depends on", dependsOn)
+	root.addGroup("needed by", neededBy)
+	return root, nil
+}
+
+func imageGraph(client *docker.Client, containers []docker.APIContainers, id string) (*graphNode, error) {
+	image, err := client.InspectImage(id)
+	if err != nil {
+		return nil, err
+	}
+	img := Image{id: image.ID, tags: withoutPlaceholder(image.RepoTags, "<none>:<none>")}
+	name := "<none>"
+	if len(img.tags) > 0 {
+		name = img.tags[0]
+	}
+
+	root := &graphNode{label: "image " + name, kind: inspectImage, id: image.ID}
+	used := []*graphNode{}
+	for _, c := range containers {
+		if img.isAncestorOf(Container{ancestor: c.Image}) {
+			used = append(used, containerNode(c, ""))
+		}
+	}
+	root.addGroup("containers", used)
+	root.addGroup("parent", parentImage(image.Parent))
+	return root, nil
+}
+
+func parentImage(parent string) []*graphNode {
+	if parent == "" {
+		return nil
+	}
+	return []*graphNode{{label: shortID(parent), kind: inspectImage, id: parent}}
+}
+
+func volumeGraph(containers []docker.APIContainers, name string) *graphNode {
+	root := &graphNode{label: "volume " + name, kind: inspectVolume, id: name}
+	used := []*graphNode{}
+	for _, c := range containers {
+		for _, mnt := range c.Mounts {
+			if mnt.Name == name {
+				used = append(used, containerNode(c, "at "+mnt.Destination))
+				break
+			}
+		}
+	}
+	root.addGroup("containers", used)
+	return root
+}
+
+func (g graphView) rows() []graphRow {
+	if g.root == nil {
+		return nil
+	}
+	rows := []graphRow{{node: g.root}}
+	var walk func(n *graphNode, indent string)
+	walk = func(n *graphNode, indent string) {
+		for i, child := range n.children {
+			branch, next := "├─ ", "│  "
+			if i == len(n.children)-1 {
+				branch, next = "└─ ", "   "
+			}
+			rows = append(rows, graphRow{node: child, prefix: indent + branch})
+			walk(child, indent+next)
+		}
+	}
+	walk(g.root, "")
+	return rows
+}
+
+func (m *model) startGraph(kind, id, name string) tea.Cmd {
+	m.graph = graphView{kind: kind, id: id, name: name, loading: true}
+	m.openDrill(drillGraph)
+	return fetchGraph(kind, id, name)
+}
+
+func openGraph(m model) (tea.Model, tea.Cmd) {
+	if m.cursor < 0 || m.cursor >= getCurrentViewItemCount(m) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	switch m.page {
+	case pageContainer:
+		c := m.containers[m.cursor]
+		cmd = m.startGraph(inspectContainer, c.id, c.name)
+	case pageImage:
+		img := m.images[m.cursor]
+		cmd = m.startGraph(inspectImage, img.id, img.name)
+	case pageVolume:
+		v := m.volumes[m.cursor]
+		cmd = m.startGraph(inspectVolume, v.name, v.name)
+	}
+	return m, cmd
+}
+
+// jumpTo go to the page of an object and put the cursor on it, networks
+// have no page so they are inspected instead
+func (m *model) jumpTo(kind, id string) tea.Cmd {
+	pages := map[string]int{
+		inspectContainer: pageContainer,
+		inspectImage:     pageImage,
+		inspectVolume:    pageVolume,
+	}
+	if kind == inspectNetwork {
+		return m.startInspect(inspectNetwork, id, id)
+	}
+	page, ok := pages[kind]
+	if !ok {
+		return nil
+	}
+
+	m.closeDrill()
+	m.setPage(page)
+	for i := 0; i < getCurrentViewItemCount(*m); i++ {
+		if m.itemID(i) == id {
+			m.cursor = i
+			return nil
+		}
+	}
+	m.logs = fmt.Sprintf("🚧 %s %s not found, it may be gone\n", kind, shortID(id))
+	return nil
+}
+
+func handleGraphKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.graph.rows()
+	if m.drillCursor >= len(rows) {
+		return m, nil
+	}
+	n := rows[m.drillCursor].node
+
+	switch {
+	case key.Matches(msg, m.keys.GoTo): // go to the page of node
+		cmd := m.jumpTo(n.kind, n.id)
+		return m, cmd
+	case key.Matches(msg, m.keys.Graph): // center graph on node
+		if n.kind != "" && n.kind != inspectNetwork {
+			name, _, _ := strings.Cut(strings.TrimPrefix(n.label, "image "), " (")
+			if n.kind == inspectVolume {
+				name = n.id // label has the mount point
+			}
+			cmd := m.startGraph(n.kind, n.id, name)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func buildGraphView(m model) (string, string) {
+	g := m.graph
+	title := fmt.Sprintf("Relations of %s %s", g.kind, g.name)
+	if g.kind == graphPods {
+		title = "Pods"
+	}
+	switch {
+	case g.loading:
+		return title, "Loading..."
+	case g.err != nil:
+		return title, "🚧 " + g.err.Error()
+	}
+
+	lines := []string{}
+	for _, r := range g.rows() {
+		label := r.node.label
+		if r.node.kind == "" {
+			label = graphGroupStyle.Render(label)
+		}
+		lines = append(lines, r.prefix+label)
+	}
+	return title, renderRows(lines, m.drillCursor, m.drillOffset, drillHeight(m))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	healthStarting  = "starting"
+	healthHealthy   = "healthy"
+	healthUnhealthy = "unhealthy"
+)
+
+// number of health check probes shown in detail pane
+const healthLogSize = 3
+
+var (
+	healthRegex   = regexp.MustCompile(`\((healthy|unhealthy|health: starting)\)`)
+	exitCodeRegex = regexp.MustCompile(`^(?:Exited|Restarting) \((-?\d+)\)`)
+)
+
+// parseHealth extract health status from docker's human readable status,
+// e.g. "Up 3 hours (unhealthy)", empty if container has no health check
+func parseHealth(status string) string {
+	match := healthRegex.FindStringSubmatch(status)
+	if match == nil {
+		return ""
+	}
+	if match[1] == "health: starting" {
+		return healthStarting
+	}
+	return match[1]
+}
+
+// parseExitCode extract last exit code from status, e.g. "Exited (137) 3 minutes ago"
+func parseExitCode(status string) (int, bool) {
+	match := exitCodeRegex.FindStringSubmatch(status)
+	if match == nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(match[1])
+	return code, err == nil
+}
+
+func formatHealth(health docker.Health) string {
+	style, ok := healthStyleMap[health.Status]
+	if !ok {
+		return "none"
+	}
+	s := style.Render(health.Status)
+	if health.FailingStreak > 0 {
+		s += fmt.Sprintf(" (%d failing)", health.FailingStreak)
+	}
+	return s
+}
+
+// formatHealthLog render the last healthLogSize probe outputs, newest first
+func formatHealthLog(logs []docker.HealthCheck) string {
+	var s string
+	for i := len(logs) - 1; i >= 0 && i >= len(logs)-healthLogSize; i-- {
+		probe := logs[i]
+		output := strings.TrimSpace(probe.Output)
+		if idx := strings.Index(output, "\n"); idx >= 0 {
+			output = output[:idx]
+		}
+		output = printable(output) // written by the probe, in the container
+		line := fmt.Sprintf("%s [%d] %s", probe.End.Format("15:04:05"), probe.ExitCode, output)
+		s += strings.Repeat(" ", 10) + runewidth.Truncate(line, fixedBodyRWidth-10, "...") + "\n"
+	}
+	return strings.TrimSuffix(s, "\n")
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
-	"sort"
+	"strings"
+	"time"
+	"unicode"
 
 	tea "github.com/charmbracelet/bubbletea"
 	docker "github.com/fsouza/go-dockerclient"
@@ -25,47 +28,118 @@ const (
 	off
 )
 
+// pending action must be observed as completed within this time
+const actionTimeout = 30 * time.Second
+
+// process is a pending docker action on a container/image/volume
+type process struct {
+	name         string // target name, for reporting
+	action       string // e.g. "stopping"
+	desiredState string // "x" means the target should be gone
+	startedAt    time.Time
+	timeout      time.Duration
+	done         bool  // the api call has returned
+	err          error // error returned by the api call
+	entry        int   // id of the history entry
+}
+
 // checkProcess check if the container is in m.processes
 // (a.k.a process is in progress)
-func checkProcess(id string, processes map[string]string) bool {
+func checkProcess(id string, processes map[string]process) bool {
 	if _, ok := processes[id]; ok {
 		return true
 	}
 	return false
 }
 
-// updatePendingProcesses cross check the actual state of container
-// with the desired state in m.processes
-// if the state match, remove the container from m.processes
-// implies that the action is completed
-// return the updated m.processes
-func updatePendingProcesses(m model) map[string]string {
-	containers := m.containers
-	for _, c := range containers {
-		id := c.id
-		state := c.state
-		desiredState := m.processes[id]
-		if _, ok := m.processes[id]; ok {
-			if state == desiredState {
-				delete(m.processes, id)
-			}
+// targetExists check if a container/image/volume with id is still listed
+func targetExists(m model, id string) bool {
+	for _, c := range m.containers {
+		if c.id == id {
+			return true
+		}
+	}
+	for _, img := range m.images {
+		if img.id == id {
+			return true
+		}
+	}
+	for _, v := range m.volumes {
+		if v.name == id {
+			return true
+		}
+	}
+	return false
+}
+
+// observedState return the current state of container with id,
+// "x" if there's no such container
+func observedState(m model, id string) string {
+	for _, c := range m.containers {
+		if c.id == id {
+			return c.state
 		}
 	}
-	return m.processes
+	if targetExists(m, id) {
+		return ""
+	}
+	return "x"
+}
+
+// updatePendingProcesses cross check the actual state of every target
+// with the desired state in m.processes, a process is finished when:
+//   - completed: the api call succeeded & the desired state is observed
+//   - failed: the api call returned an error
+//   - timed out: neither happen within its timeout
+//
+// finished processes are removed from m.processes, reported in logs and
+// recorded in history
+func (m *model) updatePendingProcesses() {
+	for id, p := range m.processes {
+		var outcome string
+		switch {
+		case p.done && errors.Is(p.err, errCancelled):
+			m.logs += fmt.Sprintf("🚫 Cancelled %s %s\n", p.action, p.name)
+			outcome = outcomeCancelled
+		case p.done && p.err != nil:
+			m.logs += fmt.Sprintf("❌ Failed %s %s: %v\n", p.action, p.name, p.err)
+			outcome = outcomeFailed
+		case p.done && observedState(*m, id) == p.desiredState:
+			m.logs += fmt.Sprintf("✅ Done %s %s\n", p.action, p.name)
+			outcome = outcomeCompleted
+		case time.Since(p.startedAt) > p.timeout:
+			m.logs += fmt.Sprintf("⌛ Timed out %s %s\n", p.action, p.name)
+			outcome = outcomeTimedOut
+		default:
+			continue
+		}
+		delete(m.processes, id)
+		m.recordOutcome(p.entry, id, outcome, p.err)
+	}
 }
 
 // addProcess add Process to m.processes
 // container Processes are used to control the blinkSwitch
-func addProcess(m *model, id, desiredState string) {
-	m.processes[id] = desiredState
+func addProcess(m *model, id, name, action, desiredState string) {
+	m.processes[id] = process{
+		name:         name,
+		action:       action,
+		desiredState: desiredState,
+		startedAt:    time.Now(),
+		timeout:      actionTimeout,
+	}
 }
 
-func unpauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
+// finishProcess record the api call result of a pending process
+func finishProcess(m *model, id string, err error) {
+	if p, ok := m.processes[id]; ok {
+		p.done = true
+		p.err = err
+		m.processes[id] = p
 	}
+}
 
+func unpauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -76,11 +150,12 @@ func unpauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
 		if c.state == "paused" {
-			go unpauseContainer(client, c.id)
+			jobs = append(jobs, job{id: c.id, name: c.name, op: opUnpause})
 			desiredState := "running"
-			addProcess(&m, c.id, desiredState)
+			addProcess(&m, c.id, c.name, "unpausing", desiredState)
 			res.success = append(res.success, c)
 		} else {
 			res.failed = append(res.failed, c)
@@ -102,17 +177,13 @@ func unpauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
 			itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 	}
 
+	m.submit("unpausing", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	return m, nil
 }
 
 func pauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
-	}
-
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -123,11 +194,12 @@ func pauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
 		if c.state == "running" {
-			go pauseContainer(client, c.id)
+			jobs = append(jobs, job{id: c.id, name: c.name, op: opPause})
 			desiredState := "paused"
-			addProcess(&m, c.id, desiredState)
+			addProcess(&m, c.id, c.name, "pausing", desiredState)
 			res.success = append(res.success, c)
 		} else {
 			res.failed = append(res.failed, c)
@@ -149,17 +221,13 @@ func pauseAndWriteLog(m model) (tea.Model, tea.Cmd) {
 			itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 	}
 
+	m.submit("pausing", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	return m, nil
 }
 
 func stopAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
-	}
-
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -170,11 +238,12 @@ func stopAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
 		if c.state == "running" || c.state == "restarting" {
-			go stopContainer(client, c.id)
+			jobs = append(jobs, job{id: c.id, name: c.name, op: opStop})
 			desiredState := "exited"
-			addProcess(&m, c.id, desiredState)
+			addProcess(&m, c.id, c.name, "stopping", desiredState)
 			res.success = append(res.success, c)
 		} else {
 			res.failed = append(res.failed, c)
@@ -196,17 +265,13 @@ func stopAndWriteLog(m model) (tea.Model, tea.Cmd) {
 			itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 	}
 
+	m.submit("stopping", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	return m, nil
 }
 
 func startAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
-	}
-
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -217,11 +282,12 @@ func startAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
 		if c.state == "exited" || c.state == "created" {
-			go startContainer(client, c.id)
+			jobs = append(jobs, job{id: c.id, name: c.name, op: opStart})
 			desiredState := "running"
-			addProcess(&m, c.id, desiredState)
+			addProcess(&m, c.id, c.name, "starting", desiredState)
 			res.success = append(res.success, c)
 
 		} else {
@@ -244,17 +310,13 @@ func startAndWriteLog(m model) (tea.Model, tea.Cmd) {
 			itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 	}
 
+	m.submit("starting", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	return m, nil
 }
 
 func removeAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
-	}
-
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -265,10 +327,11 @@ func removeAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
-		removeContainer(client, c.id)
+		jobs = append(jobs, job{id: c.id, name: c.name, op: opRemoveContainer})
 		desiredState := "x"
-		addProcess(&m, c.id, desiredState)
+		addProcess(&m, c.id, c.name, "removing", desiredState)
 		res.success = append(res.success, c)
 	}
 
@@ -277,23 +340,19 @@ func removeAndWriteLog(m model) (tea.Model, tea.Cmd) {
 
 	if successCount > 0 {
 		logs += fmt.Sprintf(
-			"🔫 Removed %v container(s)\n",
+			"🔫 Removing %v container(s), this can't be undone\n",
 			itemCountStyle.Render(fmt.Sprintf("%d", successCount)))
 	}
 
+	m.submit("removing", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	// prevent pointing to an nil index
 	m.cursor = -1
 	return m, nil
 }
 
 func restartAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
-	}
-
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -304,11 +363,12 @@ func restartAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
 		if c.state == "running" {
-			go restartContainer(client, c.id)
+			jobs = append(jobs, job{id: c.id, name: c.name, op: opRestart})
 			desiredState := "running"
-			addProcess(&m, c.id, desiredState)
+			addProcess(&m, c.id, c.name, "restarting", desiredState)
 			res.success = append(res.success, c)
 		} else {
 			res.failed = append(res.failed, c)
@@ -330,17 +390,13 @@ func restartAndWriteLog(m model) (tea.Model, tea.Cmd) {
 			itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 	}
 
+	m.submit("restarting", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	return m, nil
 }
 
 func killAndWriteLog(m model) (tea.Model, tea.Cmd) {
-	client, err := docker.NewClientFromEnv()
-	if err != nil {
-		log.Fatalf("failed to create Docker client: %v", err)
-	}
-
 	targets := []Container{}
 	if len(m.selected) == 0 {
 		targets = append(targets, m.containers[m.cursor])
@@ -351,11 +407,12 @@ func killAndWriteLog(m model) (tea.Model, tea.Cmd) {
 	}
 
 	res := actionResultContainers{}
+	jobs := []job{}
 	for _, c := range targets {
 		if c.state == "running" {
-			go killContainer(client, c.id)
+			jobs = append(jobs, job{id: c.id, name: c.name, op: opKill})
 			desiredState := "exited"
-			addProcess(&m, c.id, desiredState)
+			addProcess(&m, c.id, c.name, "killing", desiredState)
 			res.success = append(res.success, c)
 		} else {
 			res.failed = append(res.failed, c)
@@ -377,39 +434,51 @@ func killAndWriteLog(m model) (tea.Model, tea.Cmd) {
 			itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 	}
 
+	m.submit("killing", jobs)
 	m.logs = logs
-	m.selected = make(map[int]struct{})
+	m.clearSelection()
 	return m, nil
 }
 
+// getContainers list all containers, sizes are fetched apart as they are
+// slow to compute, see fetchContainerSizes
 func getContainers() []Container {
-	client, err := docker.NewClientFromEnv()
+	client, err := newClient()
 	if err != nil {
 		log.Fatalf("failed to create Docker client: %v", err)
 	}
+	// pods are best effort, containers are listed without
+	pods, _ := podMembership(client)
 	containers := []Container{}
 	for _, c := range listContainers(client, true) {
-		name := c.Names[0][1:]
-		status := c.State
 		c := Container{
-			name:     name,
-			state:    status,
+			name:     apiContainerName(c),
+			state:    normalizeState(c.State),
+			pod:      pods[c.ID].PodName,
+			status:   c.Status,
 			id:       c.ID,
 			ancestor: c.Image,
+			created:  time.Unix(c.Created, 0),
+			labels:   c.Labels,
+			health:   parseHealth(c.Status),
 		}
 		containers = append(containers, c)
 	}
 	return containers
 }
 
-func getImages() []Image {
-	client, err := docker.NewClientFromEnv()
+// getImages list images, containers are used to count the usage of each
+func getImages(containers []Container) []Image {
+	client, err := newClient()
 	if err != nil {
 		log.Fatalf("failed to create Docker client: %v", err)
 	}
+
 	images := []Image{}
 	for _, c := range listImages(client, true) {
-		tags := c.RepoTags
+		// older daemons report <none> placeholders instead of nothing
+		tags := withoutPlaceholder(c.RepoTags, "<none>:<none>")
+		digests := withoutPlaceholder(c.RepoDigests, "<none>@<none>")
 		var name string
 		if len(tags) > 0 {
 			name = tags[0]
@@ -417,13 +486,66 @@ func getImages() []Image {
 			// dangling image
 			name = "<none>"
 		}
-		c := Image{name: name, id: c.ID}
+		c := Image{
+			name:        name,
+			id:          c.ID,
+			size:        c.Size,
+			virtualSize: c.VirtualSize,
+			created:     time.Unix(c.Created, 0),
+			tags:        tags,
+			digests:     digests,
+		}
+		for _, ctr := range containers {
+			if c.isAncestorOf(ctr) {
+				c.containers++
+			}
+		}
 		images = append(images, c)
 
 	}
 	return images
 }
 
+func withoutPlaceholder(l []string, placeholder string) []string {
+	filtered := []string{}
+	for _, s := range l {
+		if s != placeholder {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered
+}
+
+// dangling image has no tag, it's usually an old build
+func (img Image) dangling() bool {
+	return len(img.tags) == 0
+}
+
+// isAncestorOf tell if container was created from img, the container
+// report the image as it was given: a tag, a tag without :latest, a digest
+// or an id
+func (img Image) isAncestorOf(c Container) bool {
+	ref := c.ancestor
+	for _, tag := range img.tags {
+		if ref == tag || ref+":latest" == tag {
+			return true
+		}
+	}
+	for _, digest := range img.digests {
+		if ref == digest {
+			return true
+		}
+	}
+	id := strings.TrimPrefix(img.id, "sha256:")
+	ref = strings.TrimPrefix(ref, "sha256:")
+	return len(ref) >= 12 && strings.HasPrefix(id, ref)
+}
+
+// unused image is tagged but no container is created from it
+func (img Image) unused() bool {
+	return !img.dangling() && img.containers == 0
+}
+
 // ---------------- Volume ----------------
 func filterContainersByVolume(c *docker.Client, volName string) []docker.APIContainers {
 	opts := docker.ListContainersOptions{
@@ -438,7 +560,7 @@ func filterContainersByVolume(c *docker.Client, volName string) []docker.APICont
 }
 
 func getVolumes() []Volume {
-	client, err := docker.NewClientFromEnv()
+	client, err := newClient()
 	if err != nil {
 		log.Fatalf("failed to create Docker clinet: %v", err)
 	}
@@ -459,16 +581,16 @@ func getVolumes() []Volume {
 		volumes = append([]Volume{volume}, volumes...)
 
 	}
+	return volumes
+}
 
-	// sort: show newest volume at the top
-	sort.Slice(volumes, func(i, j int) bool {
-		vi, vj := volumes[i], volumes[j]
-		// sort by volume name if vi & vj has the same createdAt
-		if vi.createdAt.Equal(vj.createdAt) {
-			return vi.name < vj.name
+// printable drop control characters but tabs, output of containers can't
+// then send escape sequences (title, clipboard, ...) to the terminal
+func printable(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != '\t' && unicode.IsControl(r) {
+			return -1
 		}
-		return volumes[i].createdAt.After(volumes[j].createdAt)
-	})
-
-	return volumes
+		return r
+	}, s)
 }
@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestPrintable(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"plain line", "plain line"},
+		{"tab\tkept", "tab\tkept"},
+		{"crlf\r", "crlf"},
+		{"\x1b]0;pwned\a title", "]0;pwned title"},
+		{"\x1b]52;c;ZWNobyBwd25lZA==\x07clip", "]52;c;ZWNobyBwd25lZA==clip"},
+		{"\x1b[31mred\x1b[0m", "[31mred[0m"},
+		{"c1 \u009b31m csi", "c1 31m csi"},
+		{"ünïcode ✅ 🐳", "ünïcode ✅ 🐳"},
+	}
+	for _, tt := range tests {
+		if got := printable(tt.in); got != tt.want {
+			t.Errorf("printable(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const journalFileName = "history.jsonl"
+
+const (
+	outcomePending   = "pending"
+	outcomeCompleted = "completed"
+	outcomeFailed    = "failed"
+	outcomeTimedOut  = "timed out"
+	outcomeCancelled = "cancelled"
+)
+
+type historyTarget struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	PriorState string `json:"prior_state,omitempty"` // container state before the action
+	Outcome    string `json:"outcome"`
+	Error      string `json:"error,omitempty"`
+}
+
+// historyEntry is one action on one or more targets, it's appended to the
+// journal once every target has an outcome
+type historyEntry struct {
+	ID      int             `json:"-"`
+	Time    time.Time       `json:"time"`
+	Action  string          `json:"action"`
+	User    string          `json:"user,omitempty"`
+	Host    string          `json:"host,omitempty"`
+	Targets []historyTarget `json:"targets"`
+	Undone  bool            `json:"-"`
+}
+
+func (e historyEntry) finished() bool {
+	for _, t := range e.Targets {
+		if t.Outcome == outcomePending {
+			return false
+		}
+	}
+	return true
+}
+
+// stateDir follow XDG base directory spec, default to ~/.local/state
+func stateDir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "killer-whale"), nil
+}
+
+// appendJournal append entry as a json line to the journal
+func appendJournal(entry historyEntry) error {
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+// submit hand jobs to the executor and record them as a history entry
+func (m *model) submit(action string, jobs []job) {
+	if len(jobs) == 0 {
+		return
+	}
+	if m.dryRunMode {
+		m.dryRun(action, m.jobCommands(jobs))
+		return
+	}
+
+	targets := []historyTarget{}
+	for _, j := range jobs {
+		targets = append(targets, historyTarget{ID: j.id, Name: j.name})
+	}
+	entryID := m.record(action, targets...)
+	for _, j := range jobs {
+		if p, ok := m.processes[j.id]; ok {
+			p.entry = entryID
+			m.processes[j.id] = p
+		}
+	}
+	m.executor.submit(action, jobs)
+}
+
+// record add a history entry of action on targets, their outcome is pending
+// until set by recordOutcome or recordResult, return the entry id
+func (m *model) record(action string, targets ...historyTarget) int {
+	hostname, _ := os.Hostname()
+	entry := historyEntry{
+		ID:     len(m.history) + 1,
+		Time:   time.Now(),
+		Action: action,
+		User:   os.Getenv("USER"),
+		Host:   hostname,
+	}
+	for _, t := range targets {
+		t.PriorState = containerState(*m, t.ID)
+		t.Outcome = outcomePending
+		entry.Targets = append(entry.Targets, t)
+	}
+	m.history = append(m.history, entry)
+	return entry.ID
+}
+
+// recordResult set the outcome of every pending target of an entry, for
+// actions done in a single api call
+func (m *model) recordResult(entryID int, err error) {
+	if entryID < 1 || entryID > len(m.history) {
+		return
+	}
+	for _, t := range m.history[entryID-1].Targets {
+		m.recordOutcome(entryID, t.ID, outcomeOf(err), err)
+	}
+}
+
+func outcomeOf(err error) string {
+	if err != nil {
+		return outcomeFailed
+	}
+	return outcomeCompleted
+}
+
+// recordOutcome set the outcome of target id in history entry, the entry
+// is written to the journal once all its targets are done
+func (m *model) recordOutcome(entryID int, id, outcome string, err error) {
+	if entryID < 1 || entryID > len(m.history) {
+		return
+	}
+	entry := &m.history[entryID-1]
+	for i, t := range entry.Targets {
+		if t.ID == id && t.Outcome == outcomePending {
+			entry.Targets[i].Outcome = outcome
+			if err != nil {
+				entry.Targets[i].Error = err.Error()
+			}
+		}
+	}
+	if entry.finished() {
+		if err := appendJournal(*entry); err != nil {
+			m.logs += "🚧 Failed to write history journal: " + err.Error() + "\n"
+		}
+	}
+}
+
+// ----------------------------- history view -----------------------------
+
+var outcomeIcons = map[string]string{
+	outcomePending:   "⏳",
+	outcomeCompleted: "✅",
+	outcomeFailed:    "❌",
+	outcomeTimedOut:  "⌛",
+	outcomeCancelled: "🚫",
+}
+
+// historyAt return the i-th history entry as listed, newest first
+func historyAt(m model, i int) historyEntry {
+	return m.history[len(m.history)-1-i]
+}
+
+func buildHistoryDesc(entry historyEntry) string {
+	desc := fmt.Sprintf("Time    : %s\n", entry.Time.Format("2006-01-02 15:04:05"))
+	desc += fmt.Sprintf("Action  : %s\n", entry.Action)
+	switch {
+	case entry.Undone:
+		desc += "Undo    : undone\n"
+	case entry.undoable():
+		desc += "Undo    : available\n"
+	case !strings.HasPrefix(entry.Action, undoPrefix):
+		desc += fmt.Sprintf("Undo    : %s\n", notUndoableStyle.Render("not undoable"))
+	}
+	desc += fmt.Sprintf("Targets : %d\n", len(entry.Targets))
+	for _, t := range entry.Targets {
+		id := t.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		line := fmt.Sprintf("%s %s (%s) %s", outcomeIcons[t.Outcome], t.Name, id, t.Outcome)
+		desc += "  " + runewidth.Truncate(line, fixedBodyRWidth-2, "...") + "\n"
+		if t.PriorState != "" {
+			desc += "     was " + t.PriorState + "\n"
+		}
+		if t.Error != "" {
+			desc += "     " + runewidth.Truncate(t.Error, fixedBodyRWidth-5, "...") + "\n"
+		}
+	}
+	return desc
+}
+
+func buildHistoryView(m model) (string, string) {
+	var bodyL, bodyR string
+	for i := range m.history {
+		entry := historyAt(m, i)
+		cursor := " "
+		if m.cursor == i {
+			cursor = "❯"
+			bodyR = buildCursorDesc(m)
+		}
+
+		icon := outcomeIcons[outcomeCompleted]
+		for _, t := range entry.Targets {
+			if t.Outcome != outcomeCompleted {
+				icon = outcomeIcons[t.Outcome]
+				break
+			}
+		}
+		if entry.Undone {
+			icon = "↩️"
+		}
+		name := fmt.Sprintf("%s %s %s (%d)", entry.Time.Format("15:04:05"), icon, entry.Action, len(entry.Targets))
+		name = runewidth.Truncate(name, fixedBodyLWidth-2, "...")
+		row := fmt.Sprintf("%s %s", cursor, padItemName(name, fixedBodyLWidth-2))
+		bodyL += row
+	}
+
+	padBodyHeight(&bodyL, len(m.history)+2)
+	return bodyLStyle.Render(bodyL), bodyRStyle.Render(scrollLines(bodyR, m.descOffset))
+}
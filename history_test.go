@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readJournal return the entries written to the journal
+func readJournal(t *testing.T, dir string) []historyEntry {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, "killer-whale", journalFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	entries := []historyEntry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestRecordResult(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	m := model{containers: []Container{{id: "abc123", name: "web", state: "running"}}}
+
+	rename := m.record("renaming", historyTarget{ID: "abc123", Name: "web"})
+	if got := m.history[rename-1].Targets[0]; got.Outcome != outcomePending || got.PriorState != "running" {
+		t.Errorf("target = %+v, want pending & running", got)
+	}
+	if journal := readJournal(t, dir); len(journal) != 0 {
+		t.Errorf("journal = %+v before the result", journal)
+	}
+
+	m.recordResult(rename, nil)
+	commit := m.record("committing to web:snapshot", historyTarget{ID: "abc123", Name: "web"})
+	m.recordResult(commit, errors.New("no space left on device"))
+
+	journal := readJournal(t, dir)
+	if len(journal) != 2 {
+		t.Fatalf("journal = %+v, want 2 entries", journal)
+	}
+	if got := journal[0].Targets[0]; journal[0].Action != "renaming" || got.Outcome != outcomeCompleted {
+		t.Errorf("journal[0] = %+v, want completed renaming", journal[0])
+	}
+	if got := journal[1].Targets[0]; got.Outcome != outcomeFailed || got.Error != "no space left on device" {
+		t.Errorf("journal[1] = %+v, want failed commit", journal[1])
+	}
+}
+
+func TestRecordCustomActionCancelled(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", dir)
+	m := model{}
+	m.output.entry = m.record("running tail",
+		historyTarget{ID: "a", Name: "web"}, historyTarget{ID: "b", Name: "db"})
+	m.output.run = 1
+
+	mm, _ := handleOutput(m, OutputMsg{run: 1, finished: "a", ch: make(chan OutputMsg)})
+	m = mm.(model)
+	m.finishOutput() // closed before db ran
+
+	targets := m.history[0].Targets
+	if targets[0].Outcome != outcomeCompleted || targets[1].Outcome != outcomeCancelled {
+		t.Errorf("targets = %+v, want completed & cancelled", targets)
+	}
+	if journal := readJournal(t, dir); len(journal) != 1 {
+		t.Errorf("journal = %+v, want the run", journal)
+	}
+}
@@ -0,0 +1,537 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	inspectJSON int = iota
+	inspectYAML
+)
+
+const (
+	inspectContainer = "container"
+	inspectImage     = "image"
+	inspectVolume    = "volume"
+	inspectNetwork   = "network"
+)
+
+// networksPath is where networks of a container live in its inspect
+const networksPath = "NetworkSettings.Networks"
+
+// inspectNode is a value of the inspect document, keys keep their order
+type inspectNode struct {
+	key      string // key in parent object, empty for array items
+	path     string // e.g. Config.Env[0]
+	kind     byte   // '{', '[' or 0 for scalars
+	value    any    // scalar: string, json.Number, bool or nil
+	children []*inspectNode
+	parent   *inspectNode
+}
+
+func (n *inspectNode) composite() bool {
+	return n.kind != 0
+}
+
+// inspectView is the state of the full inspect drill-down
+type inspectView struct {
+	kind      string
+	id        string
+	name      string
+	root      *inspectNode
+	err       error
+	loading   bool
+	format    int
+	collapsed map[string]bool // map[path]collapsed
+	query     string
+	parent    *inspectView // inspect to return to on esc
+}
+
+type inspectRow struct {
+	node    *inspectNode
+	depth   int
+	closing bool // closing brace in json
+}
+
+type InspectMsg struct {
+	kind string
+	id   string
+	root *inspectNode
+	err  error
+}
+
+func fetchInspect(kind, id string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return InspectMsg{kind: kind, id: id, err: err}
+		}
+
+		var v any
+		switch kind {
+		case inspectContainer:
+			v, err = client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: id})
+		case inspectImage:
+			v, err = client.InspectImage(id)
+		case inspectVolume:
+			v, err = client.InspectVolume(id)
+		case inspectNetwork:
+			v, err = client.NetworkInfo(id)
+		}
+		if err != nil {
+			return InspectMsg{kind: kind, id: id, err: err}
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return InspectMsg{kind: kind, id: id, err: err}
+		}
+		root, err := parseInspect(data)
+		return InspectMsg{kind: kind, id: id, root: root, err: err}
+	}
+}
+
+// parseInspect decode json into a tree, unlike a map the key order is kept
+func parseInspect(data []byte) (*inspectNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	root := &inspectNode{}
+	return root, decodeNode(dec, root)
+}
+
+func decodeNode(dec *json.Decoder, n *inspectNode) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		n.value = tok
+		return nil
+	}
+
+	n.kind = byte(delim)
+	for dec.More() {
+		child := &inspectNode{parent: n}
+		if n.kind == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			child.key = tok.(string)
+			child.path = child.key
+			if n.path != "" {
+				child.path = n.path + "." + child.key
+			}
+		} else {
+			child.path = fmt.Sprintf("%s[%d]", n.path, len(n.children))
+		}
+		if err := decodeNode(dec, child); err != nil {
+			return err
+		}
+		n.children = append(n.children, child)
+	}
+	_, err = dec.Token() // closing delim
+	return err
+}
+
+// flatten list the visible children of n, collapsed may be nil
+func flatten(n *inspectNode, depth, format int, collapsed map[string]bool) []inspectRow {
+	rows := []inspectRow{}
+	for _, child := range n.children {
+		rows = append(rows, inspectRow{node: child, depth: depth})
+		if !child.composite() || collapsed[child.path] || len(child.children) == 0 {
+			continue
+		}
+		rows = append(rows, flatten(child, depth+1, format, collapsed)...)
+		if format == inspectJSON {
+			rows = append(rows, inspectRow{node: child, depth: depth, closing: true})
+		}
+	}
+	return rows
+}
+
+func (v inspectView) rows() []inspectRow {
+	if v.root == nil {
+		return nil
+	}
+	return flatten(v.root, 0, v.format, v.collapsed)
+}
+
+func isLastChild(n *inspectNode) bool {
+	siblings := n.parent.children
+	return siblings[len(siblings)-1] == n
+}
+
+// renderRow render a row as a line of json or yaml
+func renderRow(r inspectRow, format int, collapsed map[string]bool) string {
+	n := r.node
+	indent := strings.Repeat("  ", r.depth)
+	open := !collapsed[n.path] && len(n.children) > 0
+
+	if format == inspectJSON {
+		comma := ","
+		if isLastChild(n) {
+			comma = ""
+		}
+		if r.closing {
+			return indent + closingDelim(n.kind) + comma
+		}
+		label := ""
+		if n.parent.kind == '{' {
+			label = jsonScalar(n.key) + ": "
+		}
+		switch {
+		case !n.composite():
+			return indent + label + jsonScalar(n.value) + comma
+		case open:
+			return indent + label + string(n.kind)
+		}
+		return indent + label + collapsedValue(n) + comma
+	}
+
+	label := "- "
+	if n.parent.kind == '{' {
+		label = yamlScalar(n.key) + ": "
+	}
+	switch {
+	case !n.composite():
+		return indent + label + yamlScalar(n.value)
+	case open:
+		return indent + strings.TrimSuffix(label, " ")
+	}
+	return indent + label + collapsedValue(n)
+}
+
+func closingDelim(kind byte) string {
+	if kind == '{' {
+		return "}"
+	}
+	return "]"
+}
+
+// collapsedValue is the short form of a collapsed or empty object/array
+func collapsedValue(n *inspectNode) string {
+	if len(n.children) == 0 {
+		return string(n.kind) + closingDelim(n.kind)
+	}
+	return fmt.Sprintf("%c…%s (%d)", n.kind, closingDelim(n.kind), len(n.children))
+}
+
+func jsonScalar(v any) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// yamlScalar render a scalar, strings are quoted only when yaml would read
+// them as something else
+func yamlScalar(v any) string {
+	s, ok := v.(string)
+	if !ok {
+		if v == nil {
+			return "null"
+		}
+		return fmt.Sprint(v)
+	}
+
+	quote := s == "" ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@` ") ||
+		strings.HasSuffix(s, " ") ||
+		strings.Contains(s, ": ") ||
+		strings.Contains(s, " #") ||
+		strings.ContainsAny(s, "\n\t")
+	switch strings.ToLower(s) {
+	case "true", "false", "null", "yes", "no", "on", "off", "~":
+		quote = true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		quote = true
+	}
+	if quote {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// serialize render n and everything below it, used for copying
+func serialize(n *inspectNode, format int) string {
+	if !n.composite() {
+		if s, ok := n.value.(string); ok {
+			return s
+		}
+		return jsonScalar(n.value)
+	}
+
+	lines := []string{}
+	depth := 0
+	if format == inspectJSON {
+		lines = append(lines, string(n.kind))
+		depth = 1
+	}
+	for _, r := range flatten(n, depth, format, nil) {
+		lines = append(lines, renderRow(r, format, nil))
+	}
+	if format == inspectJSON {
+		lines = append(lines, closingDelim(n.kind))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matches list the nodes whose key or value contain the query, in order
+func (v inspectView) matches() []*inspectNode {
+	if v.root == nil || v.query == "" {
+		return nil
+	}
+	query := strings.ToLower(v.query)
+	found := []*inspectNode{}
+	var walk func(n *inspectNode)
+	walk = func(n *inspectNode) {
+		for _, child := range n.children {
+			text := child.key
+			if !child.composite() {
+				text += " " + fmt.Sprint(child.value)
+			}
+			if strings.Contains(strings.ToLower(text), query) {
+				found = append(found, child)
+			}
+			walk(child)
+		}
+	}
+	walk(v.root)
+	return found
+}
+
+// reveal expand every parent of n so it become visible
+func (v inspectView) reveal(n *inspectNode) {
+	for p := n.parent; p != nil; p = p.parent {
+		delete(v.collapsed, p.path)
+	}
+}
+
+// rowOf return the row index of n, -1 if hidden
+func rowOf(rows []inspectRow, n *inspectNode) int {
+	for i, r := range rows {
+		if r.node == n && !r.closing {
+			return i
+		}
+	}
+	return -1
+}
+
+// jumpToMatch move cursor to the next (or previous) match after cursor
+func (m *model) jumpToMatch(forward bool) {
+	found := m.inspect.matches()
+	if len(found) == 0 {
+		m.logs = fmt.Sprintf("🔍 No match for %q\n", m.inspect.query)
+		return
+	}
+
+	// position of cursor among all nodes
+	order := map[*inspectNode]int{}
+	var i int
+	var walk func(n *inspectNode)
+	walk = func(n *inspectNode) {
+		for _, child := range n.children {
+			order[child] = i
+			i++
+			walk(child)
+		}
+	}
+	walk(m.inspect.root)
+
+	current := -1
+	if rows := m.inspect.rows(); m.drillCursor >= 0 && m.drillCursor < len(rows) {
+		current = order[rows[m.drillCursor].node]
+	}
+	target := found[0]
+	if !forward {
+		target = found[len(found)-1]
+	}
+	for j := range found {
+		if forward && order[found[j]] > current {
+			target = found[j]
+			break
+		}
+		k := len(found) - 1 - j
+		if !forward && order[found[k]] < current {
+			target = found[k]
+			break
+		}
+	}
+
+	m.inspect.reveal(target)
+	rows := m.inspect.rows()
+	m.drillCursor = 0
+	m.moveDrillCursor(rowOf(rows, target), len(rows))
+	m.logs = fmt.Sprintf("🔍 %s\n", target.path)
+}
+
+// startInspect open the full inspect of an object, over the current one if
+// any so esc come back to it
+func (m *model) startInspect(kind, id, name string) tea.Cmd {
+	var parent *inspectView
+	if m.drill == drillInspect {
+		current := m.inspect
+		parent = &current
+	}
+	m.inspect = inspectView{
+		kind:      kind,
+		id:        id,
+		name:      name,
+		loading:   true,
+		collapsed: make(map[string]bool),
+		parent:    parent,
+	}
+	m.openDrill(drillInspect)
+	return fetchInspect(kind, id)
+}
+
+// closeInspect go back to the parent inspect, true if there was one
+func (m *model) closeInspect() bool {
+	parent := m.inspect.parent
+	if parent == nil {
+		return false
+	}
+	m.inspect = *parent
+	m.drillCursor = 0
+	m.drillOffset = 0
+	return true
+}
+
+func openInspect(m model) (tea.Model, tea.Cmd) {
+	if getCurrentViewItemCount(m) == 0 || m.cursor < 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	switch m.page {
+	case pageContainer:
+		c := m.containers[m.cursor]
+		cmd = m.startInspect(inspectContainer, c.id, c.name)
+	case pageImage:
+		img := m.images[m.cursor]
+		cmd = m.startInspect(inspectImage, img.id, img.name)
+	case pageVolume:
+		v := m.volumes[m.cursor]
+		cmd = m.startInspect(inspectVolume, v.name, v.name)
+	}
+	return m, cmd
+}
+
+// collapseNested collapse everything below the top level, the full inspect
+// of a container is a few hundred lines
+func collapseNested(n *inspectNode, collapsed map[string]bool) {
+	for _, child := range n.children {
+		if child.composite() {
+			collapsed[child.path] = true
+			collapseNested(child, collapsed)
+		}
+	}
+}
+
+func handleInspectKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.inspect.rows()
+	var n *inspectNode
+	if m.drillCursor < len(rows) {
+		n = rows[m.drillCursor].node
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Toggle): // expand/collapse
+		if n != nil && len(n.children) > 0 {
+			m.inspect.collapsed[n.path] = !m.inspect.collapsed[n.path]
+			m.drillCursor = 0
+			m.moveDrillCursor(rowOf(m.inspect.rows(), n), drillRowCount(m))
+		}
+
+	case key.Matches(msg, m.keys.Format): // json/yaml
+		m.inspect.format = (m.inspect.format + 1) % 2
+		if n != nil {
+			m.drillCursor = 0
+			m.moveDrillCursor(rowOf(m.inspect.rows(), n), drillRowCount(m))
+		}
+
+	case key.Matches(msg, m.keys.Search): // search keys & values
+		m.openPrompt("search", m.inspect.query, func(m model, query string) (model, tea.Cmd) {
+			m.inspect.query = strings.TrimSpace(query)
+			if m.inspect.query != "" {
+				m.drillCursor = -1 // search from the top
+				m.jumpToMatch(true)
+			}
+			return m, nil
+		})
+
+	case key.Matches(msg, m.keys.NextMatch):
+		m.jumpToMatch(true)
+
+	case key.Matches(msg, m.keys.PrevMatch):
+		m.jumpToMatch(false)
+
+	case key.Matches(msg, m.keys.Copy): // copy value or subtree
+		if n == nil {
+			return m, nil
+		}
+		if err := copyToClipboard(serialize(n, m.inspect.format)); err != nil {
+			m.logs = "❌ Failed to copy: " + err.Error() + "\n"
+		} else {
+			m.logs = fmt.Sprintf("📋 Copied %s\n", n.path)
+		}
+
+	case key.Matches(msg, m.keys.Jump): // inspect the network under cursor
+		for p := n; p != nil && p.parent != nil; p = p.parent {
+			if p.parent.path == networksPath && m.inspect.kind == inspectContainer {
+				cmd := m.startInspect(inspectNetwork, p.key, p.key)
+				return m, cmd
+			}
+		}
+		m.logs = "🚧 Move the cursor onto a network to inspect it\n"
+	}
+	return m, nil
+}
+
+func buildInspectView(m model) (string, string) {
+	v := m.inspect
+	format := "json"
+	if v.format == inspectYAML {
+		format = "yaml"
+	}
+	title := fmt.Sprintf("Inspect %s %s", v.kind, v.name) + sortStyle.Render("  ("+format+")")
+	if v.query != "" {
+		title += sortStyle.Render(fmt.Sprintf("  /%s: %d match(es)", v.query, len(v.matches())))
+	}
+
+	switch {
+	case v.loading:
+		return title, "Loading..."
+	case v.err != nil:
+		return title, "🚧 " + v.err.Error()
+	}
+
+	matched := map[*inspectNode]bool{}
+	for _, n := range v.matches() {
+		matched[n] = true
+	}
+
+	lines := []string{}
+	for _, r := range v.rows() {
+		line := runewidth.Truncate(renderRow(r, v.format, v.collapsed), fullWidth-14, "…")
+		if matched[r.node] && !r.closing {
+			line = matchStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return title, renderRows(lines, m.drillCursor, m.drillOffset, drillHeight(m))
+}
@@ -15,7 +15,18 @@ type keyMap struct {
 	Page1     key.Binding
 	Page2     key.Binding
 	Page3     key.Binding
+	Page4     key.Binding
+	Page5     key.Binding
 	Toggle    key.Binding
+	Sort      key.Binding
+	Visual    key.Binding
+	Invert    key.Binding
+
+	SelectExited    key.Binding
+	SelectUnhealthy key.Binding
+	SelectImage     key.Binding
+	SelectProject   key.Binding
+	SortOrder       key.Binding
 
 	Remove  key.Binding
 	Clean   key.Binding
@@ -25,11 +36,49 @@ type keyMap struct {
 	Start   key.Binding
 	Pause   key.Binding
 	Unpause key.Binding
+	Cancel  key.Binding
+	Undo    key.Binding
+	Diff    key.Binding
+	Filter  key.Binding
+	Top     key.Binding
+	Signal  key.Binding
+
+	Files    key.Binding
+	Open     key.Binding
+	Download key.Binding
+	Upload   key.Binding
+	Confirm  key.Binding
+
+	Inspect   key.Binding
+	Format    key.Binding
+	Search    key.Binding
+	NextMatch key.Binding
+	PrevMatch key.Binding
+	Copy      key.Binding
+	Jump      key.Binding
+
+	Graph key.Binding
+	GoTo  key.Binding
+
+	Rename     key.Binding
+	EditLimits key.Binding
+	Commit     key.Binding
+	Watch      key.Binding
+	Pin        key.Binding
+	PinnedOnly key.Binding
+	Pods       key.Binding
+	Palette    key.Binding
+	DryRun     key.Binding
+	NextField  key.Binding
+	PrevField  key.Binding
+
+	Custom []key.Binding // custom actions of the page, from config
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
 		k.Help,
+		k.Palette,
 		k.Quit,
 		k.SelectAll,
 		k.Tab,
@@ -42,6 +91,7 @@ func (k keyMap) FullHelp() [][]key.Binding {
 			k.Quit,
 			k.Tab,
 			k.Help,
+			k.Palette,
 			k.Up,
 			k.Down,
 		},
@@ -49,8 +99,16 @@ func (k keyMap) FullHelp() [][]key.Binding {
 			k.Toggle,
 			k.Clear,
 			k.SelectAll,
+			k.Visual,
+			k.Invert,
+			k.SelectExited,
+			k.SelectUnhealthy,
+			k.SelectImage,
+			k.SelectProject,
 			k.Pause,
 			k.Unpause,
+			k.Sort,
+			k.SortOrder,
 		},
 		{
 			k.Remove,
@@ -59,10 +117,28 @@ func (k keyMap) FullHelp() [][]key.Binding {
 			k.Stop,
 			k.Start,
 			k.Clean,
+			k.Cancel,
+			k.Undo,
+			k.Diff,
+			k.Top,
+			k.Files,
+			k.Inspect,
+			k.Graph,
+			k.Rename,
+			k.EditLimits,
+			k.Commit,
+			k.Watch,
+			k.Pin,
+			k.PinnedOnly,
+			k.Pods,
+			k.DryRun,
 			k.Page1,
 			k.Page2,
 			k.Page3,
+			k.Page4,
+			k.Page5,
 		},
+		k.Custom,
 	}
 }
 
@@ -108,10 +184,50 @@ var keys = keyMap{
 		key.WithKeys("3"),
 		key.WithHelp("3", "volumes"),
 	),
+	Page4: key.NewBinding(
+		key.WithKeys("4"),
+		key.WithHelp("4", "history"),
+	),
+	Page5: key.NewBinding(
+		key.WithKeys("5"),
+		key.WithHelp("5", "system"),
+	),
 	Toggle: key.NewBinding(
 		key.WithKeys(" ", "enter"),
 		key.WithHelp("space/enter", "toggle selection"),
 	),
+	Visual: key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "visual select"),
+	),
+	Invert: key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "invert selection"),
+	),
+	SelectExited: key.NewBinding(
+		key.WithKeys("e"),
+		key.WithHelp("e", "select exited"),
+	),
+	SelectUnhealthy: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "select unhealthy"),
+	),
+	SelectImage: key.NewBinding(
+		key.WithKeys("I"),
+		key.WithHelp("shift+i", "select same image"),
+	),
+	SelectProject: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "select same compose project"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("o"),
+		key.WithHelp("o", "sort by"),
+	),
+	SortOrder: key.NewBinding(
+		key.WithKeys("O"),
+		key.WithHelp("shift+o", "reverse sort"),
+	),
 	Remove: key.NewBinding(
 		key.WithKeys("X"),
 		key.WithHelp("shift+x", "remove"),
@@ -144,4 +260,128 @@ var keys = keyMap{
 		key.WithKeys("P"),
 		key.WithHelp("shift+p", "unpause"),
 	),
+	Diff: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "filesystem diff"),
+	),
+	Top: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "processes"),
+	),
+	Signal: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("shift+s", "send signal"),
+	),
+	Files: key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "browse files"),
+	),
+	Open: key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("enter", "open"),
+	),
+	Download: key.NewBinding(
+		key.WithKeys("D"),
+		key.WithHelp("shift+d", "download"),
+	),
+	Upload: key.NewBinding(
+		key.WithKeys("L"),
+		key.WithHelp("shift+l", "upload"),
+	),
+	Graph: key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "relations graph"),
+	),
+	GoTo: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "go to"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("R"),
+		key.WithHelp("shift+r", "rename"),
+	),
+	EditLimits: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("shift+e", "edit limits"),
+	),
+	Commit: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "commit to image"),
+	),
+	Watch: key.NewBinding(
+		key.WithKeys("W"),
+		key.WithHelp("shift+w", "watch"),
+	),
+	Pin: key.NewBinding(
+		key.WithKeys("*"),
+		key.WithHelp("*", "pin"),
+	),
+	PinnedOnly: key.NewBinding(
+		key.WithKeys("F"),
+		key.WithHelp("shift+f", "pinned only"),
+	),
+	Pods: key.NewBinding(
+		key.WithKeys("G"),
+		key.WithHelp("shift+g", "pods"),
+	),
+	DryRun: key.NewBinding(
+		key.WithKeys("z"),
+		key.WithHelp("z", "dry run"),
+	),
+	Palette: key.NewBinding(
+		key.WithKeys(":", "ctrl+p"),
+		key.WithHelp(":/ctrl+p", "commands"),
+	),
+	NextField: key.NewBinding(
+		key.WithKeys("tab", "down"),
+		key.WithHelp("tab/↓", "next field"),
+	),
+	PrevField: key.NewBinding(
+		key.WithKeys("shift+tab", "up"),
+		key.WithHelp("shift+tab/↑", "prev field"),
+	),
+	Confirm: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("enter", "confirm"),
+	),
+	Inspect: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "inspect"),
+	),
+	Format: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "json/yaml"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("shift+n", "prev match"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("y"),
+		key.WithHelp("y", "copy"),
+	),
+	Jump: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "inspect network"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "toggle filter"),
+	),
+	Undo: key.NewBinding(
+		key.WithKeys("U"),
+		key.WithHelp("shift+u", "undo last action"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "cancel queued"),
+	),
 }
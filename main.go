@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -8,8 +9,13 @@ import (
 )
 
 func main() {
+	readOnlyFlag := flag.Bool("read-only", false, "look but don't touch, refuse every action that change something")
+	flag.StringVar(&endpoint, "host", "", "daemon to connect to, e.g. ssh://user@host or tcp://host:2375 (default $DOCKER_HOST)")
+	flag.StringVar(&endpoint, "H", "", "shorthand for --host")
+	flag.Parse()
+
 	p := tea.NewProgram(
-		initialModel(),
+		initialModel(*readOnlyFlag),
 		tea.WithMouseCellMotion(),
 	)
 	if _, err := p.Run(); err != nil {
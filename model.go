@@ -1,6 +1,7 @@
 package main
 
 import (
+	"log"
 	"time"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -11,9 +12,15 @@ import (
 type Container struct {
 	name     string
 	state    string
+	status   string // human readable status, e.g. "Up 3 hours"
 	id       string
 	ancestor string
 	desc     string
+	created  time.Time
+	labels   map[string]string
+	health   string // starting|healthy|unhealthy, empty if no health check
+	pod      string // podman only
+	size     int64  // writable layer, only listed when sorted by size
 }
 
 type Volume struct {
@@ -24,8 +31,14 @@ type Volume struct {
 }
 
 type Image struct {
-	id   string
-	name string
+	id          string
+	name        string
+	size        int64
+	virtualSize int64
+	created     time.Time
+	tags        []string
+	digests     []string
+	containers  int // containers (in any state) created from it
 }
 
 const (
@@ -33,23 +46,61 @@ const (
 	pageImage
 	pageVolume
 	pageLog
+	pageSystem
 )
 
 type model struct {
-	containers  []Container
-	images      []Image
-	volumes     []Volume
-	cursor      int
-	selected    map[int]struct{}
-	blinkSwitch int
+	containers []Container
+	images     []Image
+	volumes    []Volume
+	cursor     int
+	selected   map[int]struct{}
+	// visual mode: select range between anchor & cursor
+	visual       bool
+	visualAnchor string              // id of item where visual mode began
+	visualBase   map[string]struct{} // ids selected before entering visual mode
+	blinkSwitch  int
 	// TODO: merge process into Container struct
-	processes map[string]string // map[containerID]desiredState
-	keys      keyMap
-	help      help.Model
-	logs      string
-	page      int
-	width     int
-	height    int
+	processes map[string]process // map[containerID]process
+	executor  *executor
+	progress  map[int]JobDoneMsg // map[batch]last finished job
+	history   []historyEntry     // oldest first
+	// drill-down view, see drill.go
+	drill        int
+	drillCursor  int
+	drillOffset  int
+	diff         diffView
+	top          topView
+	files        filesView
+	inspect      inspectView
+	graph        graphView
+	system       systemView
+	prune        pruneWizard
+	prompt       *prompt         // active text input, nil if none
+	form         *form           // active form, nil if none
+	newImage     string          // id of the last committed image, highlighted
+	watched      map[string]bool // map[containerName]watched, override config rules
+	events       chan *docker.APIEvents
+	toast        *toast
+	pinnedOnly   bool     // hide unpinned containers
+	palette      *palette // command palette, nil if closed
+	output       outputView
+	dryRunMode   bool   // actions render their docker commands instead
+	dryRunLog    string // shown instead of logs until the next key
+	dryRunScript string // last dry run as a shell script
+	keys         keyMap
+	help         help.Model
+	logs         string
+	page         int
+	width        int
+	height       int
+	descOffset   int                       // scroll offset of detail pane
+	descLines    int                       // line count of detail pane of descLinesOf
+	descLinesOf  string                    // page & id of item under cursor
+	sorts        map[int]sortOrder         // map[page]sortOrder
+	stats        map[string]containerStats // map[containerID]containerStats
+	sizes        containerSizes
+	config       config
 }
 
 // fast tick rate doesn't seems to affect performance (average 20 container)
@@ -69,17 +120,32 @@ func doTick() tea.Cmd {
 }
 
 func (m model) Init() tea.Cmd {
-	return doTick()
+	return tea.Batch(doTick(), collectStats, m.executor.listen(), listenEvents(m.events))
 }
 
-func initialModel() model {
+func initialModel(readOnlyFlag bool) model {
 	cursor := 0
 
+	// config, broken config shouldn't stop the app from starting
+	cfg, err := loadConfig()
+	var logs string
+	if err != nil {
+		logs = "🚧 Failed to load config: " + err.Error() + "\n"
+	}
+
+	readOnly = readOnlyFlag || isReadOnlyContext(cfg)
+
 	// containers
+	sorts := loadSorts(cfg)
 	containers := getContainers()
-	images := getImages()
+	images := getImages(containers)
 	volumes := getVolumes()
 
+	// sort
+	sortContainers(containers, sorts[pageContainer], nil, cfg.pinSet())
+	sortImages(images, sorts[pageImage])
+	sortVolumes(volumes, sorts[pageVolume])
+
 	// descriptions of container at cursor
 	if len(containers) > 0 {
 		containers[cursor].desc = buildContainerDescShort(containers[cursor].id)
@@ -90,16 +156,42 @@ func initialModel() model {
 	h.Width = fullWidth
 
 	// processes
-	processes := make(map[string]string)
-	return model{
+	processes := make(map[string]process)
+	client, err := newClient()
+	if err != nil {
+		log.Fatalf("failed to create Docker client: %v", err)
+	}
+	events, err := subscribeEvents(client)
+	if err != nil {
+		logs += "🚧 Failed to listen to docker events, no notifications: " + err.Error() + "\n"
+	}
+	concurrency := cfg.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultConcurrency
+	}
+	var b backend = dockerBackend{client: client}
+	if readOnly {
+		b = readOnlyBackend{}
+	}
+	m := model{
 		cursor:     0,
 		containers: containers,
 		images:     images,
 		volumes:    volumes,
 		selected:   make(map[int]struct{}),
 		processes:  processes,
+		executor:   newExecutor(b, concurrency),
+		progress:   make(map[int]JobDoneMsg),
 		page:       pageContainer,
 		keys:       keys,
 		help:       h,
+		logs:       logs,
+		sorts:      sorts,
+		stats:      make(map[string]containerStats),
+		config:     cfg,
+		watched:    make(map[string]bool),
+		events:     events,
 	}
+	m.keys = m.togglePageKey() // custom actions
+	return m
 }
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// screen layout, used to map mouse position back to a component
+const (
+	titleRow = 0
+	bodyPadL = 4 // bodyLStyle padding left
+)
+
+// bodyRowTop return the row of the first list item: title, app border &
+// body padding top, toast, status & prompt are all below the body
+func bodyRowTop(m model) int {
+	return lipgloss.Height(titleStyle.Render(buildTitleView(m))) + 2
+}
+
+// appMarginLeft mirror the margin applied to appStyle in View
+func appMarginLeft(m model) int {
+	if m.width > fullWidth {
+		return (m.width - fullWidth) / 2
+	}
+	return 0
+}
+
+// detailPaneX return the column where detail pane (body R) begin
+func detailPaneX(m model) int {
+	return appMarginLeft(m) + 1 + bodyPadL + fixedBodyLWidth
+}
+
+func handleMouse(m model, msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	// overlays only take keys
+	if m.prompt != nil || m.form != nil || m.palette != nil {
+		return m, nil
+	}
+	if m.drill != drillNone {
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.moveDrillCursor(-1, drillRowCount(m))
+		case tea.MouseButtonWheelDown:
+			m.moveDrillCursor(1, drillRowCount(m))
+		}
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		if msg.X >= detailPaneX(m) {
+			scrollDesc(&m, -1)
+		} else {
+			moveCursor(&m, -1)
+		}
+
+	case msg.Button == tea.MouseButtonWheelDown:
+		if msg.X >= detailPaneX(m) {
+			scrollDesc(&m, 1)
+		} else {
+			moveCursor(&m, 1)
+		}
+
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		if msg.Y == titleRow {
+			clickTitle(&m, msg.X)
+		} else {
+			clickRow(&m, msg)
+		}
+	}
+	return m, nil
+}
+
+// clickTitle switch to the page of the tab under x
+func clickTitle(m *model, x int) {
+	title, tabs := buildTitleText(*m)
+	offset := outerComponentPad(longestLineWidth(title), m.width)
+	for _, tab := range tabs {
+		if x >= offset+tab.start && x < offset+tab.end {
+			m.setPage(tab.page)
+			return
+		}
+	}
+}
+
+// clickRow move cursor to the clicked row,
+// ctrl+click toggle the row, shift+click select from cursor to the row
+func clickRow(m *model, msg tea.MouseMsg) {
+	if msg.X >= detailPaneX(*m) {
+		return
+	}
+	row := msg.Y - bodyRowTop(*m)
+	if row < 0 || row >= getCurrentViewItemCount(*m) {
+		return
+	}
+
+	switch {
+	case msg.Ctrl:
+		if _, ok := m.selected[row]; ok {
+			delete(m.selected, row)
+		} else {
+			m.selected[row] = struct{}{}
+		}
+		m.logs = ""
+	case msg.Shift:
+		from, to := m.cursor, row
+		if from > to {
+			from, to = to, from
+		}
+		for i := from; i <= to; i++ {
+			m.selected[i] = struct{}{}
+		}
+		m.logs = ""
+	}
+	m.cursor = row
+	m.descOffset = 0
+	m.extendVisual()
+}
+
+// moveCursor move cursor by delta without wrapping around
+func moveCursor(m *model, delta int) {
+	cursor := m.cursor + delta
+	if cursor < 0 || cursor >= getCurrentViewItemCount(*m) {
+		return
+	}
+	m.cursor = cursor
+	m.descOffset = 0
+	m.extendVisual()
+}
+
+// scrollDesc scroll the detail pane, its line count is only counted again
+// when the cursor moved to another item, building it may inspect a container
+func scrollDesc(m *model, delta int) {
+	if of := fmt.Sprint(m.page, m.itemID(m.cursor)); of != m.descLinesOf {
+		m.descLines = strings.Count(buildCursorDesc(*m), "\n")
+		m.descLinesOf = of
+	}
+	offset := m.descOffset + delta
+	if offset < 0 || offset >= m.descLines {
+		return
+	}
+	m.descOffset = offset
+}
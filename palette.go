@@ -0,0 +1,278 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+const paletteHeight = 12
+
+// paletteEntry is an action of the palette, run by pressing its binding,
+// {items} in title is replaced by the item name of the current page
+type paletteEntry struct {
+	title   string
+	binding func(k keyMap) key.Binding
+	pages   []int // pages the title apply to, all if empty
+}
+
+var paletteEntries = []paletteEntry{
+	{title: "switch to containers", binding: func(k keyMap) key.Binding { return k.Page1 }},
+	{title: "switch to images", binding: func(k keyMap) key.Binding { return k.Page2 }},
+	{title: "switch to volumes", binding: func(k keyMap) key.Binding { return k.Page3 }},
+	{title: "switch to history", binding: func(k keyMap) key.Binding { return k.Page4 }},
+	{title: "switch to system", binding: func(k keyMap) key.Binding { return k.Page5 }},
+
+	{title: "start selected containers", binding: func(k keyMap) key.Binding { return k.Start }},
+	{title: "stop selected containers", binding: func(k keyMap) key.Binding { return k.Stop }},
+	{title: "restart selected containers", binding: func(k keyMap) key.Binding { return k.Restart }},
+	{title: "kill selected containers", binding: func(k keyMap) key.Binding { return k.Kill }},
+	{title: "pause selected containers", binding: func(k keyMap) key.Binding { return k.Pause }},
+	{title: "unpause selected containers", binding: func(k keyMap) key.Binding { return k.Unpause }},
+	{title: "remove selected {items}", binding: func(k keyMap) key.Binding { return k.Remove }},
+	{title: "remove dangling images", binding: func(k keyMap) key.Binding { return k.Clean }, pages: []int{pageImage}},
+	{title: "prune selected categories", binding: func(k keyMap) key.Binding { return k.Clean }, pages: []int{pageSystem}},
+	{title: "undo last action", binding: func(k keyMap) key.Binding { return k.Undo }},
+	{title: "cancel queued actions", binding: func(k keyMap) key.Binding { return k.Cancel }},
+
+	{title: "show filesystem diff of container", binding: func(k keyMap) key.Binding { return k.Diff }},
+	{title: "show processes of container", binding: func(k keyMap) key.Binding { return k.Top }},
+	{title: "browse files of container", binding: func(k keyMap) key.Binding { return k.Files }},
+	{title: "inspect {item}", binding: func(k keyMap) key.Binding { return k.Inspect }},
+	{title: "show relations graph of {item}", binding: func(k keyMap) key.Binding { return k.Graph }},
+	{title: "rename container", binding: func(k keyMap) key.Binding { return k.Rename }},
+	{title: "edit restart policy & resource limits", binding: func(k keyMap) key.Binding { return k.EditLimits }},
+	{title: "commit container to image", binding: func(k keyMap) key.Binding { return k.Commit }},
+	{title: "watch selected containers", binding: func(k keyMap) key.Binding { return k.Watch }},
+	{title: "pin selected containers", binding: func(k keyMap) key.Binding { return k.Pin }},
+	{title: "show pinned containers only", binding: func(k keyMap) key.Binding { return k.PinnedOnly }},
+	{title: "show containers grouped by pod", binding: func(k keyMap) key.Binding { return k.Pods }},
+
+	{title: "toggle selection", binding: func(k keyMap) key.Binding { return k.Toggle }},
+	{title: "select all {items}", binding: func(k keyMap) key.Binding { return k.SelectAll }},
+	{title: "invert selection", binding: func(k keyMap) key.Binding { return k.Invert }},
+	{title: "visual select", binding: func(k keyMap) key.Binding { return k.Visual }},
+	{title: "select exited containers", binding: func(k keyMap) key.Binding { return k.SelectExited }},
+	{title: "select unhealthy containers", binding: func(k keyMap) key.Binding { return k.SelectUnhealthy }},
+	{title: "select containers of the same image", binding: func(k keyMap) key.Binding { return k.SelectImage }},
+	{title: "select containers of the same compose project", binding: func(k keyMap) key.Binding { return k.SelectProject }},
+	{title: "change sort of {items}", binding: func(k keyMap) key.Binding { return k.Sort }},
+	{title: "reverse sort of {items}", binding: func(k keyMap) key.Binding { return k.SortOrder }},
+
+	{title: "toggle dry run", binding: func(k keyMap) key.Binding { return k.DryRun }},
+	{title: "toggle help", binding: func(k keyMap) key.Binding { return k.Help }},
+	{title: "quit", binding: func(k keyMap) key.Binding { return k.Quit }},
+}
+
+var pageItems = map[int][2]string{ // singular, plural
+	pageContainer: {"container", "containers"},
+	pageImage:     {"image", "images"},
+	pageVolume:    {"volume", "volumes"},
+	pageLog:       {"history entry", "history entries"},
+	pageSystem:    {"category", "categories"},
+}
+
+type paletteAction struct {
+	title   string
+	binding key.Binding
+	run     func(m model) (tea.Model, tea.Cmd) // instead of the binding, if set
+	score   int
+}
+
+// palette is the command palette, it fuzzy search the actions of the
+// current page and run the chosen one by replaying its key
+type palette struct {
+	input   textinput.Model
+	actions []paletteAction // matching actions, best first
+	cursor  int
+}
+
+// pageActions return the actions bound on the current page
+func pageActions(m model) []paletteAction {
+	item, items := pageItems[m.page][0], pageItems[m.page][1]
+
+	actions := []paletteAction{}
+	for _, e := range paletteEntries {
+		b := e.binding(m.keys)
+		if !b.Enabled() || (len(e.pages) > 0 && !containsInt(e.pages, m.page)) {
+			continue
+		}
+		title := strings.ReplaceAll(e.title, "{items}", items)
+		title = strings.ReplaceAll(title, "{item}", item)
+		actions = append(actions, paletteAction{title: title, binding: b})
+	}
+
+	for i, a := range m.pageCustomActions() {
+		if i >= len(m.keys.Custom) {
+			break // unbound in read-only mode
+		}
+		action := a
+		actions = append(actions, paletteAction{
+			title:   "run " + a.Name,
+			binding: m.keys.Custom[i],
+			run:     func(m model) (tea.Model, tea.Cmd) { return runCustomAction(m, action) },
+		})
+	}
+
+	// prune a single category, whatever is under the cursor
+	if m.page == pageSystem && m.keys.Clean.Enabled() {
+		for i, name := range dfCategoryNames {
+			category := i
+			actions = append(actions, paletteAction{
+				title:   "prune " + strings.ToLower(name),
+				binding: m.keys.Clean,
+				run: func(m model) (tea.Model, tea.Cmd) {
+					m.clearSelection()
+					m.cursor = category
+					return openPruneWizard(m)
+				},
+			})
+		}
+	}
+	return actions
+}
+
+func containsInt(list []int, n int) bool {
+	for _, v := range list {
+		if v == n {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyScore match query as a case insensitive subsequence of s, the
+// higher the better, consecutive runes & word starts score more
+func fuzzyScore(query, s string) (int, bool) {
+	q := []rune(strings.ToLower(strings.ReplaceAll(query, " ", "")))
+	r := []rune(strings.ToLower(s))
+	score, qi, streak := 0, 0, 0
+	for i := 0; i < len(r) && qi < len(q); i++ {
+		if r[i] != q[qi] {
+			streak = 0
+			continue
+		}
+		streak++
+		score += streak
+		if i == 0 || !unicode.IsLetter(r[i-1]) {
+			score += 3 // word start
+		}
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func (p *palette) filter(m model) {
+	query := p.input.Value()
+	p.actions = []paletteAction{}
+	for _, a := range pageActions(m) {
+		if score, ok := fuzzyScore(query, a.title); ok {
+			a.score = score
+			p.actions = append(p.actions, a)
+		}
+	}
+	sort.SliceStable(p.actions, func(i, j int) bool {
+		return p.actions[i].score > p.actions[j].score
+	})
+	p.cursor = 0
+}
+
+func openPalette(m model) (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = ": "
+	input.Placeholder = "type to search actions"
+	input.Focus()
+	m.palette = &palette{input: input}
+	m.palette.filter(m)
+	return m, textinput.Blink
+}
+
+// keyMsgOf build the key press of a binding key, e.g. "X" or "enter"
+func keyMsgOf(k string) tea.KeyMsg {
+	named := map[string]tea.KeyType{
+		"enter":     tea.KeyEnter,
+		"tab":       tea.KeyTab,
+		"shift+tab": tea.KeyShiftTab,
+		"esc":       tea.KeyEsc,
+		"up":        tea.KeyUp,
+		"down":      tea.KeyDown,
+		"ctrl+c":    tea.KeyCtrlC,
+	}
+	if t, ok := named[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	if k == " " {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(k)}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+func handlePaletteKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.palette
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.palette = nil
+		return m, nil
+	case tea.KeyUp, tea.KeyCtrlP:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return m, nil
+	case tea.KeyDown, tea.KeyCtrlN:
+		if p.cursor < len(p.actions)-1 {
+			p.cursor++
+		}
+		return m, nil
+	case tea.KeyEnter:
+		m.palette = nil
+		if p.cursor >= len(p.actions) {
+			return m, nil
+		}
+		if a := p.actions[p.cursor]; a.run != nil {
+			return a.run(m)
+		}
+		return m.Update(keyMsgOf(p.actions[p.cursor].binding.Keys()[0]))
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.filter(m)
+	return m, cmd
+}
+
+func paletteHelp() drillKeyMap {
+	return drillKeyMap{
+		key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("↑/↓", "move")),
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "run")),
+		key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "close")),
+	}
+}
+
+func buildPaletteView(m model) string {
+	p := m.palette
+	offset := 0
+	if p.cursor >= paletteHeight {
+		offset = p.cursor - paletteHeight + 1
+	}
+
+	width := fullWidth - 16
+	lines := []string{}
+	for _, a := range p.actions {
+		binding := sortStyle.Render(a.binding.Help().Key)
+		title := runewidth.Truncate(a.title, width-lipgloss.Width(binding)-2, "…")
+		title += strings.Repeat(" ", width-runewidth.StringWidth(title)-lipgloss.Width(binding))
+		lines = append(lines, title+binding)
+	}
+	body := renderRows(lines, p.cursor, offset, paletteHeight)
+	if len(p.actions) == 0 {
+		body = "No matching action."
+	}
+	return drillStyle.Render(lipgloss.JoinVertical(lipgloss.Left,
+		drillTitleStyle.Render("Command palette"), p.input.View(), "", body))
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pinSet return the pinned container names, pins are by name so they
+// survive recreation of the container
+func (c config) pinSet() map[string]bool {
+	pins := make(map[string]bool, len(c.Pins))
+	for _, name := range c.Pins {
+		pins[name] = true
+	}
+	return pins
+}
+
+// visibleContainers hide the unpinned containers in pinned only mode
+func (m model) visibleContainers(containers []Container) []Container {
+	if !m.pinnedOnly {
+		return containers
+	}
+	pins := m.config.pinSet()
+	visible := []Container{}
+	for _, c := range containers {
+		if pins[c.name] {
+			visible = append(visible, c)
+		}
+	}
+	return visible
+}
+
+// togglePin pin/unpin the selected containers, or the one under cursor,
+// and persist the pins to the user config
+func togglePin(m model) (tea.Model, tea.Cmd) {
+	targets := []Container{}
+	for i := range m.selected {
+		targets = append(targets, m.containers[i])
+	}
+	if len(targets) == 0 {
+		targets = append(targets, m.containers[m.cursor])
+	}
+
+	// pin all unless all are already pinned
+	pins := m.config.pinSet()
+	pin := false
+	for _, c := range targets {
+		if !pins[c.name] {
+			pin = true
+		}
+	}
+	for _, c := range targets {
+		if pin {
+			pins[c.name] = true
+		} else {
+			delete(pins, c.name)
+		}
+	}
+	m.config.Pins = []string{}
+	for name := range pins {
+		m.config.Pins = append(m.config.Pins, name)
+	}
+	sort.Strings(m.config.Pins)
+
+	action := "Pinned"
+	if !pin {
+		action = "Unpinned"
+	}
+	m.logs = fmt.Sprintf("📌 %s %s container(s)\n", action, itemCountStyle.Render(fmt.Sprint(len(targets))))
+	if err := saveConfig(m.config); err != nil {
+		m.logs += "🚧 Failed to save config: " + err.Error() + "\n"
+	}
+
+	cursorID, selectedIDs := m.itemID(m.cursor), m.selectedIDs()
+	m.containers = m.visibleContainers(m.containers)
+	m.applySort()
+	m.restoreSelection(cursorID, selectedIDs)
+	return m, nil
+}
+
+// togglePinnedOnly show only the pinned containers, or all
+func togglePinnedOnly(m model) (tea.Model, tea.Cmd) {
+	m.pinnedOnly = !m.pinnedOnly
+	cursorID := m.itemID(m.cursor)
+	m.clearSelection()
+	m.containers = m.visibleContainers(m.sizes.apply(getContainers()))
+	m.applySort()
+	m.restoreSelection(cursorID, nil)
+	if m.cursor < 0 && len(m.containers) > 0 {
+		m.cursor = 0
+	}
+
+	if m.pinnedOnly {
+		m.logs = fmt.Sprintf("📌 Showing %s pinned container(s) only\n", itemCountStyle.Render(fmt.Sprint(len(m.containers))))
+	} else {
+		m.logs = "📌 Showing all containers\n"
+	}
+	return m, nil
+}
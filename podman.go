@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	engineDocker = "docker"
+	enginePodman = "podman"
+
+	graphPods = "pods" // graph of all containers grouped by pod
+
+	pingTimeout = 2 * time.Second
+	// libpod endpoints, any version from podman 3 on is fine
+	libpodPrefix = "/v3.0.0/libpod"
+)
+
+var (
+	endpointOnce sync.Once
+	endpoint     string // --host, empty to use the environment like the docker cli
+	engine       = engineDocker
+)
+
+// podmanSockets are tried when no docker daemon is reachable, rootless first
+func podmanSockets() []string {
+	sockets := []string{}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return append(sockets, "/run/podman/podman.sock")
+}
+
+// newClient connect to --host, $DOCKER_HOST or the docker socket, else to
+// a podman socket, podman speak the docker API too
+func newClient() (*docker.Client, error) {
+	endpointOnce.Do(detectEndpoint)
+	return clientOf(endpoint)
+}
+
+func clientOf(endpoint string) (*docker.Client, error) {
+	if endpoint == "" {
+		return docker.NewClientFromEnv()
+	}
+	if strings.HasPrefix(endpoint, "ssh://") {
+		return newSSHClient(endpoint)
+	}
+	return docker.NewClient(endpoint)
+}
+
+func reachable(c *docker.Client) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	return c.PingWithContext(ctx) == nil
+}
+
+// detectEndpoint pick the endpoint & tell docker from podman, once
+func detectEndpoint() {
+	// go-dockerclient doesn't know ssh://, it's ours
+	if host := os.Getenv("DOCKER_HOST"); endpoint == "" && strings.HasPrefix(host, "ssh://") {
+		endpoint = host
+	}
+	if endpoint == "" && os.Getenv("DOCKER_HOST") == "" {
+		if c, err := clientOf(""); err != nil || !reachable(c) {
+			for _, socket := range podmanSockets() {
+				if _, err := os.Stat(socket); err != nil {
+					continue
+				}
+				if c, err := clientOf("unix://" + socket); err == nil && reachable(c) {
+					endpoint = "unix://" + socket
+					break
+				}
+			}
+		}
+	}
+
+	c, err := clientOf(endpoint)
+	if err != nil {
+		return
+	}
+	var version struct {
+		Components []struct{ Name string }
+	}
+	if apiRequest(c, http.MethodGet, "/version", nil, &version) != nil {
+		return
+	}
+	for _, component := range version.Components {
+		if strings.Contains(strings.ToLower(component.Name), enginePodman) {
+			engine = enginePodman
+		}
+	}
+}
+
+// normalizeState map podman states to the docker ones
+func normalizeState(state string) string {
+	switch strings.ToLower(state) {
+	case "configured", "initialized":
+		return "created"
+	case "stopped", "stopping":
+		return "exited"
+	}
+	return strings.ToLower(state)
+}
+
+// libpodContainer is a container as listed by the libpod API, which tell
+// its pod unlike the docker compatible one
+type libpodContainer struct {
+	ID      string `json:"Id"`
+	Pod     string
+	PodName string
+	IsInfra bool
+}
+
+type libpodPod struct {
+	ID         string `json:"Id"`
+	Name       string
+	Status     string
+	InfraID    string `json:"InfraId"`
+	Containers []struct {
+		ID     string `json:"Id"`
+		Names  string
+		Status string
+	}
+}
+
+// podMembership return the pod of containers by id, nil unless on podman
+func podMembership(c *docker.Client) (map[string]libpodContainer, error) {
+	if engine != enginePodman {
+		return nil, nil
+	}
+	var list []libpodContainer
+	if err := apiRequest(c, http.MethodGet, libpodPrefix+"/containers/json?all=true", nil, &list); err != nil {
+		return nil, err
+	}
+	pods := make(map[string]libpodContainer)
+	for _, lc := range list {
+		if lc.Pod != "" {
+			pods[lc.ID] = lc
+		}
+	}
+	return pods, nil
+}
+
+// podsGraph group every container by pod, containers without pod last
+func podsGraph(client *docker.Client, containers []docker.APIContainers) (*graphNode, error) {
+	var pods []libpodPod
+	if err := apiRequest(client, http.MethodGet, libpodPrefix+"/pods/json", nil, &pods); err != nil {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	byID := map[string]docker.APIContainers{}
+	for _, c := range containers {
+		byID[c.ID] = c
+	}
+
+	root := &graphNode{label: fmt.Sprintf("%d pod(s)", len(pods))}
+	inPod := map[string]bool{}
+	for _, pod := range pods {
+		children := []*graphNode{}
+		for _, pc := range pod.Containers {
+			inPod[pc.ID] = true
+			detail := ""
+			if pc.ID == pod.InfraID {
+				detail = "(infra)"
+			}
+			if c, ok := byID[pc.ID]; ok {
+				children = append(children, containerNode(c, detail))
+				continue
+			}
+			label := strings.TrimSpace(fmt.Sprintf("%s (%s) %s", pc.Names, normalizeState(pc.Status), detail))
+			children = append(children, &graphNode{label: label, kind: inspectContainer, id: pc.ID})
+		}
+		root.add(&graphNode{label: fmt.Sprintf("pod %s (%s)", pod.Name, strings.ToLower(pod.Status)), children: children})
+	}
+
+	noPod := []*graphNode{}
+	for _, c := range containers {
+		if !inPod[c.ID] {
+			noPod = append(noPod, containerNode(c, ""))
+		}
+	}
+	root.addGroup("no pod", noPod)
+	return root, nil
+}
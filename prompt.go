@@ -0,0 +1,44 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prompt is a single line text input shown in the status line, enter
+// submit the value to onSubmit, esc cancel
+type prompt struct {
+	input    textinput.Model
+	onSubmit func(m model, value string) (model, tea.Cmd)
+}
+
+func (m *model) openPrompt(label, value string, onSubmit func(m model, value string) (model, tea.Cmd)) {
+	input := textinput.New()
+	input.Prompt = label + ": "
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Focus()
+	m.prompt = &prompt{input: input, onSubmit: onSubmit}
+}
+
+func handlePromptKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.prompt = nil
+		return m, nil
+	case tea.KeyEnter:
+		p := m.prompt
+		m.prompt = nil
+		return p.onSubmit(m, p.input.Value())
+	}
+
+	var cmd tea.Cmd
+	input := m.prompt.input
+	input, cmd = input.Update(msg)
+	m.prompt = &prompt{input: input, onSubmit: m.prompt.onSubmit}
+	return m, cmd
+}
+
+func buildPromptView(m model) string {
+	return statusStyle.Render(m.prompt.input.View())
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// readOnly is set once at startup by --read-only or read_only_contexts, it
+// refuse every mutating docker call, the mutating keys are unbound too
+var readOnly bool
+
+var errReadOnly = errors.New("refused in read-only mode")
+
+// readOnlyBackend refuse every action of the executor
+type readOnlyBackend struct{}
+
+func (readOnlyBackend) StartContainer(string) error   { return errReadOnly }
+func (readOnlyBackend) StopContainer(string) error    { return errReadOnly }
+func (readOnlyBackend) RestartContainer(string) error { return errReadOnly }
+func (readOnlyBackend) KillContainer(string) error    { return errReadOnly }
+func (readOnlyBackend) PauseContainer(string) error   { return errReadOnly }
+func (readOnlyBackend) UnpauseContainer(string) error { return errReadOnly }
+func (readOnlyBackend) RemoveContainer(string) error  { return errReadOnly }
+func (readOnlyBackend) RemoveImage(string) error      { return errReadOnly }
+func (readOnlyBackend) RemoveVolume(string) error     { return errReadOnly }
+
+// currentDockerContext return the docker context in use, like the docker
+// cli: $DOCKER_CONTEXT, then currentContext of the cli config
+func currentDockerContext() string {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name
+	}
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "default"
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	var cliConfig struct {
+		CurrentContext string `json:"currentContext"`
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil || json.Unmarshal(b, &cliConfig) != nil || cliConfig.CurrentContext == "" {
+		return "default"
+	}
+	return cliConfig.CurrentContext
+}
+
+// isReadOnlyContext tell if the current docker context is listed in config
+func isReadOnlyContext(cfg config) bool {
+	current := currentDockerContext()
+	for _, name := range cfg.ReadOnlyContexts {
+		if name == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/docker/go-units"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var (
+	containerNamePattern = regexp.MustCompile(`^/?[a-zA-Z0-9][a-zA-Z0-9_.-]+$`)
+	restartPolicyPattern = regexp.MustCompile(`^(no|always|unless-stopped|on-failure(:\d+)?)$`)
+)
+
+// daemon refuse lower memory limits
+const minMemoryLimit = 6 * 1024 * 1024
+
+// HostConfigMsg carry the current settings of a container, to prefill the
+// update form
+type HostConfigMsg struct {
+	id         string
+	name       string
+	hostConfig *docker.HostConfig
+	err        error
+}
+
+// ContainerEditMsg is the result of a rename or update
+type ContainerEditMsg struct {
+	entry  int    // history entry
+	action string // e.g. "renaming"
+	name   string
+	err    error
+}
+
+func fetchHostConfig(id, name string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return HostConfigMsg{id: id, name: name, err: err}
+		}
+		ctr, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: id})
+		if err != nil {
+			return HostConfigMsg{id: id, name: name, err: err}
+		}
+		return HostConfigMsg{id: id, name: name, hostConfig: ctr.HostConfig}
+	}
+}
+
+// editContainer record the edit in history and run it
+func (m *model) editContainer(action, id, name string, edit func(c *docker.Client) error) tea.Cmd {
+	entry := m.record(action, historyTarget{ID: id, Name: name})
+	return func() tea.Msg {
+		client, err := newClient()
+		if err == nil {
+			err = edit(client)
+		}
+		return ContainerEditMsg{entry: entry, action: action, name: name, err: err}
+	}
+}
+
+func validateContainerName(name string) error {
+	if !containerNamePattern.MatchString(name) {
+		return fmt.Errorf("only [a-zA-Z0-9][a-zA-Z0-9_.-] are allowed")
+	}
+	return nil
+}
+
+func openRename(m model) (tea.Model, tea.Cmd) {
+	c := m.containers[m.cursor]
+	m.openPrompt("rename "+c.name+" to", c.name, func(m model, name string) (model, tea.Cmd) {
+		name = strings.TrimSpace(name)
+		if name == "" || name == c.name {
+			return m, nil
+		}
+		if err := validateContainerName(name); err != nil {
+			m.logs = fmt.Sprintf("🚧 Invalid name %q, %v\n", name, err)
+			return m, nil
+		}
+		if m.dryRunMode {
+			m.dryRun("renaming", []string{dockerCommand("rename", c.name, name)})
+			return m, nil
+		}
+		m.logs = fmt.Sprintf("✏️ Renaming %s to %s\n", c.name, name)
+		cmd := m.editContainer("renaming", c.id, c.name, func(client *docker.Client) error {
+			return renameContainer(client, c.id, name)
+		})
+		return m, cmd
+	})
+	return m, nil
+}
+
+// ----------------------------- update form -----------------------------
+
+// parseRestartPolicy parse no|always|unless-stopped|on-failure[:N]
+func parseRestartPolicy(s string) (docker.RestartPolicy, error) {
+	if !restartPolicyPattern.MatchString(s) {
+		return docker.RestartPolicy{}, fmt.Errorf("use no, always, unless-stopped or on-failure[:N]")
+	}
+	name, retries, _ := strings.Cut(s, ":")
+	policy := docker.RestartPolicy{Name: name}
+	if retries != "" {
+		policy.MaximumRetryCount, _ = strconv.Atoi(retries)
+	}
+	return policy, nil
+}
+
+func formatRestartPolicy(p docker.RestartPolicy) string {
+	switch {
+	case p.Name == "":
+		return "no"
+	case p.Name == "on-failure" && p.MaximumRetryCount > 0:
+		return fmt.Sprintf("on-failure:%d", p.MaximumRetryCount)
+	}
+	return p.Name
+}
+
+// optional wrap a validator so empty value (unchanged) is allowed
+func optional(validate func(string) error) func(string) error {
+	return func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return nil
+		}
+		return validate(strings.TrimSpace(s))
+	}
+}
+
+func validateRestartPolicy(s string) error {
+	_, err := parseRestartPolicy(strings.TrimSpace(s))
+	return err
+}
+
+func validateCPUShares(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 2 {
+		return fmt.Errorf("must be a number, at least 2")
+	}
+	return nil
+}
+
+func validateCPUQuota(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || (n != -1 && n < 1000) {
+		return fmt.Errorf("must be -1 (unlimited) or at least 1000µs")
+	}
+	return nil
+}
+
+func validateMemory(s string) error {
+	n, err := units.RAMInBytes(s)
+	if err != nil {
+		return fmt.Errorf("must be a size e.g. 512m, 2g")
+	}
+	if n < minMemoryLimit {
+		return fmt.Errorf("must be at least 6m")
+	}
+	return nil
+}
+
+func validatePidsLimit(s string) error {
+	if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+		return fmt.Errorf("must be a number, 0 or -1 for unlimited")
+	}
+	return nil
+}
+
+// formatLimit prefill a limit, 0 means not set
+func formatLimit(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// openUpdateForm open the restart policy & resources form prefilled from
+// the current host config
+func openUpdateForm(m model, msg HostConfigMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.logs = fmt.Sprintf("❌ Failed to inspect %s: %v\n", msg.name, msg.err)
+		return m, nil
+	}
+	hc := msg.hostConfig
+	if hc == nil {
+		hc = &docker.HostConfig{}
+	}
+	var memory, pids string
+	if hc.Memory > 0 {
+		memory = strings.ToLower(units.BytesSize(float64(hc.Memory)))
+	}
+	if hc.PidsLimit != nil {
+		pids = formatLimit(*hc.PidsLimit)
+	}
+	current := []string{
+		formatRestartPolicy(hc.RestartPolicy),
+		formatLimit(hc.CPUShares),
+		formatLimit(hc.CPUQuota),
+		memory,
+		pids,
+	}
+
+	fields := []formField{
+		newFormField("Restart policy", current[0], "no, always, unless-stopped or on-failure[:N]", validateRestartPolicy),
+		newFormField("CPU shares", current[1], "relative weight, default 1024, empty = unchanged", optional(validateCPUShares)),
+		newFormField("CPU quota", current[2], "µs per 100ms period, -1 = unlimited, empty = unchanged", optional(validateCPUQuota)),
+		newFormField("Memory limit", current[3], "e.g. 512m, 2g, empty = unchanged", optional(validateMemory)),
+		newFormField("PIDs limit", current[4], "0 or -1 = unlimited, empty = unchanged", optional(validatePidsLimit)),
+	}
+
+	id, name := msg.id, msg.name
+	m.openForm("Update "+name, fields, func(m model, values []string) (model, tea.Cmd) {
+		changes := map[string]any{}
+		flags := []string{"update"} // of docker update, for dry run
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		changed := func(i int) bool {
+			return values[i] != "" && values[i] != current[i]
+		}
+
+		if changed(0) {
+			policy, _ := parseRestartPolicy(values[0])
+			changes["RestartPolicy"] = policy
+			flags = append(flags, "--restart", values[0])
+		}
+		if changed(1) {
+			changes["CpuShares"], _ = strconv.ParseInt(values[1], 10, 64)
+			flags = append(flags, "--cpu-shares", values[1])
+		}
+		if changed(2) {
+			changes["CpuQuota"], _ = strconv.ParseInt(values[2], 10, 64)
+			flags = append(flags, "--cpu-quota", values[2])
+		}
+		if changed(3) {
+			memory, _ := units.RAMInBytes(values[3])
+			changes["Memory"] = memory
+			flags = append(flags, "--memory", values[3])
+			// keep the same amount of swap, the daemon refuse a memory
+			// limit above the current memory+swap limit
+			if hc.MemorySwap > 0 {
+				changes["MemorySwap"] = memory + hc.MemorySwap - hc.Memory
+				flags = append(flags, "--memory-swap", fmt.Sprint(changes["MemorySwap"]))
+			}
+		}
+		if changed(4) {
+			changes["PidsLimit"], _ = strconv.ParseInt(values[4], 10, 64)
+			flags = append(flags, "--pids-limit", values[4])
+		}
+
+		if len(changes) == 0 {
+			m.logs = "🚧 Nothing changed\n"
+			return m, nil
+		}
+		if m.dryRunMode {
+			m.dryRun("updating", []string{dockerCommand(append(flags, name)...)})
+			return m, nil
+		}
+		m.logs = fmt.Sprintf("✏️ Updating %s\n", name)
+		cmd := m.editContainer("updating", id, name, func(client *docker.Client) error {
+			return updateContainer(client, id, changes)
+		})
+		return m, cmd
+	})
+	return m, nil
+}
+
+func openUpdate(m model) (tea.Model, tea.Cmd) {
+	c := m.containers[m.cursor]
+	return m, fetchHostConfig(c.id, c.name)
+}
+
+func editLog(msg ContainerEditMsg) string {
+	if msg.err != nil {
+		return fmt.Sprintf("❌ Failed %s %s: %v\n", msg.action, msg.name, msg.err)
+	}
+	return fmt.Sprintf("✅ Done %s %s\n", msg.action, msg.name)
+}
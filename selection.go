@@ -0,0 +1,77 @@
+package main
+
+const composeProjectLabel = "com.docker.compose.project"
+
+// clearSelection unselect everything and leave visual mode
+func (m *model) clearSelection() {
+	m.selected = make(map[int]struct{})
+	m.visual = false
+	m.visualBase = nil
+}
+
+// toggleVisual enter visual mode anchored at cursor, or leave it while
+// keeping the selected range
+func (m *model) toggleVisual() {
+	if m.visual {
+		m.visual = false
+		m.visualBase = nil
+		return
+	}
+	m.visual = true
+	m.visualAnchor = m.itemID(m.cursor)
+	m.visualBase = m.selectedIDs()
+	m.extendVisual()
+}
+
+// extendVisual select everything between anchor and cursor, on top of what
+// was selected before entering visual mode, anchor & base are ids so they
+// follow their items when the list is sorted again
+func (m *model) extendVisual() {
+	if !m.visual {
+		return
+	}
+	m.selected = make(map[int]struct{})
+	anchor := m.cursor // anchor is gone
+	for i := 0; i < getCurrentViewItemCount(*m); i++ {
+		id := m.itemID(i)
+		if _, ok := m.visualBase[id]; ok {
+			m.selected[i] = struct{}{}
+		}
+		if id == m.visualAnchor {
+			anchor = i
+		}
+	}
+	from, to := anchor, m.cursor
+	if from > to {
+		from, to = to, from
+	}
+	for i := from; i <= to; i++ {
+		if i >= 0 && i < getCurrentViewItemCount(*m) {
+			m.selected[i] = struct{}{}
+		}
+	}
+}
+
+func (m *model) invertSelection() {
+	selected := make(map[int]struct{})
+	for i := 0; i < getCurrentViewItemCount(*m); i++ {
+		if _, ok := m.selected[i]; !ok {
+			selected[i] = struct{}{}
+		}
+	}
+	m.clearSelection()
+	m.selected = selected
+}
+
+// selectContainersWhere add every container matching predicate to the
+// selection, return how many matched
+func (m *model) selectContainersWhere(predicate func(c Container) bool) int {
+	var count int
+	for i, c := range m.containers {
+		if predicate(c) {
+			m.selected[i] = struct{}{}
+			count++
+		}
+	}
+	return count
+}
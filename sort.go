@@ -0,0 +1,395 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	sortByName    = "name"
+	sortByState   = "state"
+	sortByCreated = "created"
+	sortBySize    = "size"
+	sortByImage   = "image"
+	sortByUptime  = "uptime"
+	sortByCPU     = "cpu"
+	sortByMemory  = "memory"
+)
+
+type sortOrder struct {
+	field string
+	desc  bool
+}
+
+// sortFieldsByPage list the sortable fields of each page, in cycle order
+var sortFieldsByPage = map[int][]string{
+	pageContainer: {sortByName, sortByState, sortByCreated, sortBySize, sortByImage, sortByUptime, sortByCPU, sortByMemory},
+	pageImage:     {sortByName, sortByCreated, sortBySize},
+	pageVolume:    {sortByName, sortByCreated},
+}
+
+var defaultSortByPage = map[int]sortOrder{
+	pageContainer: {field: sortByName},
+	pageImage:     {field: sortByName},
+	pageVolume:    {field: sortByCreated, desc: true}, // newest volume at the top
+}
+
+var pageNames = map[int]string{
+	pageContainer: "containers",
+	pageImage:     "images",
+	pageVolume:    "volumes",
+	pageLog:       "history",
+	pageSystem:    "system",
+}
+
+// stateRank order container states from most to least alive
+var stateRank = map[string]int{
+	"running":    0,
+	"restarting": 1,
+	"paused":     2,
+	"created":    3,
+	"exited":     4,
+	"dead":       5,
+}
+
+// containerRank rank container by state, unhealthy container go first so
+// they're easy to spot
+func containerRank(c Container) int {
+	if c.health == healthUnhealthy {
+		return -1
+	}
+	return stateRank[c.state]
+}
+
+// loadSorts read the sort of each page from the user config,
+// falling back to the page default
+func loadSorts(cfg config) map[int]sortOrder {
+	sorts := make(map[int]sortOrder)
+	for page, def := range defaultSortByPage {
+		sorts[page] = def
+		if sc, ok := cfg.Sort[pageNames[page]]; ok && isSortField(page, sc.Field) {
+			sorts[page] = sortOrder{field: sc.Field, desc: sc.Desc}
+		}
+	}
+	return sorts
+}
+
+func isSortField(page int, field string) bool {
+	for _, f := range sortFieldsByPage[page] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// availableSortFields filter out cpu/memory while there are no stats yet
+func availableSortFields(page int, hasStats bool) []string {
+	fields := []string{}
+	for _, f := range sortFieldsByPage[page] {
+		if (f == sortByCPU || f == sortByMemory) && !hasStats {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// nextSortField return the field after current, wrapping around
+func nextSortField(fields []string, current string) string {
+	for i, f := range fields {
+		if f == current {
+			return fields[(i+1)%len(fields)]
+		}
+	}
+	return fields[0]
+}
+
+// nextSort is the sort after s on repeated presses: ascending, then
+// descending, then the next field ascending
+func nextSort(fields []string, s sortOrder) sortOrder {
+	if !s.desc && containsString(fields, s.field) {
+		return sortOrder{field: s.field, desc: true}
+	}
+	return sortOrder{field: nextSortField(fields, s.field)}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// sizeSorted tell if containers are sorted by size, their size is only
+// fetched then
+func (m model) sizeSorted() bool {
+	return m.sorts[pageContainer].field == sortBySize
+}
+
+// the daemon compute the disk usage of every container to list sizes, so
+// they are refreshed way slower than the list
+const sizeRefreshRate = time.Minute
+
+// containerSizes cache the writable layer size of containers
+type containerSizes struct {
+	size    map[string]int64 // map[containerID]size
+	at      time.Time
+	loading bool
+}
+
+type ContainerSizesMsg struct {
+	size map[string]int64
+	err  error
+}
+
+func fetchContainerSizes() tea.Msg {
+	client, err := newClient()
+	if err != nil {
+		return ContainerSizesMsg{err: err}
+	}
+	containers, err := client.ListContainers(docker.ListContainersOptions{All: true, Size: true})
+	if err != nil {
+		return ContainerSizesMsg{err: err}
+	}
+	size := make(map[string]int64)
+	for _, c := range containers {
+		size[c.ID] = c.SizeRw
+	}
+	return ContainerSizesMsg{size: size}
+}
+
+// stale tell if sizes should be fetched again
+func (s containerSizes) stale() bool {
+	return !s.loading && time.Since(s.at) > sizeRefreshRate
+}
+
+// set store fetched sizes, a failed fetch is retried after the refresh rate
+func (s *containerSizes) set(msg ContainerSizesMsg) error {
+	s.loading = false
+	s.at = time.Now()
+	if msg.err == nil {
+		s.size = msg.size
+	}
+	return msg.err
+}
+
+// apply set the cached size of containers
+func (s containerSizes) apply(containers []Container) []Container {
+	for i := range containers {
+		containers[i].size = s.size[containers[i].id]
+	}
+	return containers
+}
+
+func (s sortOrder) String() string {
+	arrow := "↑"
+	if s.desc {
+		arrow = "↓"
+	}
+	return s.field + " " + arrow
+}
+
+// lessBy apply sort direction, falling back to name so the order is stable
+// between ticks
+func lessBy(cmp int, desc bool, nameI, nameJ string) bool {
+	if cmp == 0 {
+		return nameI < nameJ
+	}
+	if desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+func compareInt(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+var uptimeRegex = regexp.MustCompile(`^Up (Less than a second|About a minute|About an hour|(\d+) (second|minute|hour|day|week|month|year)s?)`)
+
+// parseUptime convert docker's human readable status (e.g. "Up 3 hours")
+// back to a duration, non running container has 0 uptime
+func parseUptime(status string) time.Duration {
+	match := uptimeRegex.FindStringSubmatch(status)
+	if match == nil {
+		return 0
+	}
+	switch match[1] {
+	case "Less than a second":
+		return 0
+	case "About a minute":
+		return time.Minute
+	case "About an hour":
+		return time.Hour
+	}
+
+	n, _ := strconv.Atoi(match[2])
+	units := map[string]time.Duration{
+		"second": time.Second,
+		"minute": time.Minute,
+		"hour":   time.Hour,
+		"day":    24 * time.Hour,
+		"week":   7 * 24 * time.Hour,
+		"month":  30 * 24 * time.Hour,
+		"year":   365 * 24 * time.Hour,
+	}
+	return time.Duration(n) * units[match[3]]
+}
+
+// sortContainers sort containers by s, pinned ones first
+func sortContainers(containers []Container, s sortOrder, stats map[string]containerStats, pins map[string]bool) {
+	sort.SliceStable(containers, func(i, j int) bool {
+		ci, cj := containers[i], containers[j]
+		if pins[ci.name] != pins[cj.name] {
+			return pins[ci.name]
+		}
+		var cmp int
+		switch s.field {
+		case sortByName:
+			cmp = strings.Compare(ci.name, cj.name)
+		case sortByState:
+			cmp = containerRank(ci) - containerRank(cj)
+		case sortByCreated:
+			cmp = compareInt(ci.created.Unix(), cj.created.Unix())
+		case sortBySize:
+			cmp = compareInt(ci.size, cj.size)
+		case sortByImage:
+			cmp = strings.Compare(ci.ancestor, cj.ancestor)
+		case sortByUptime:
+			cmp = compareInt(int64(parseUptime(ci.status)), int64(parseUptime(cj.status)))
+		case sortByCPU:
+			cmp = compareFloat(stats[ci.id].cpuPercent, stats[cj.id].cpuPercent)
+		case sortByMemory:
+			cmp = compareInt(int64(stats[ci.id].memUsage), int64(stats[cj.id].memUsage))
+		}
+		return lessBy(cmp, s.desc, ci.name, cj.name)
+	})
+}
+
+func sortImages(images []Image, s sortOrder) {
+	sort.SliceStable(images, func(i, j int) bool {
+		ii, ij := images[i], images[j]
+		var cmp int
+		switch s.field {
+		case sortByName:
+			cmp = strings.Compare(ii.name, ij.name)
+		case sortByCreated:
+			cmp = compareInt(ii.created.Unix(), ij.created.Unix())
+		case sortBySize:
+			cmp = compareInt(ii.size, ij.size)
+		}
+		return lessBy(cmp, s.desc, ii.name, ij.name)
+	})
+}
+
+func sortVolumes(volumes []Volume, s sortOrder) {
+	sort.SliceStable(volumes, func(i, j int) bool {
+		vi, vj := volumes[i], volumes[j]
+		var cmp int
+		switch s.field {
+		case sortByName:
+			cmp = strings.Compare(vi.name, vj.name)
+		case sortByCreated:
+			cmp = compareInt(vi.createdAt.UnixNano(), vj.createdAt.UnixNano())
+		}
+		return lessBy(cmp, s.desc, vi.name, vj.name)
+	})
+}
+
+// applySort sort every list in place using the sort of its page
+func (m *model) applySort() {
+	sortContainers(m.containers, m.sorts[pageContainer], m.stats, m.config.pinSet())
+	sortImages(m.images, m.sorts[pageImage])
+	sortVolumes(m.volumes, m.sorts[pageVolume])
+}
+
+// itemID return the id (name for volume) of the i-th item on current page
+func (m model) itemID(i int) string {
+	if i < 0 || i >= getCurrentViewItemCount(m) {
+		return ""
+	}
+	switch m.page {
+	case pageContainer:
+		return m.containers[i].id
+	case pageImage:
+		return m.images[i].id
+	case pageVolume:
+		return m.volumes[i].name
+	case pageLog:
+		return fmt.Sprintf("%d", historyAt(m, i).ID)
+	case pageSystem:
+		return dfCategoryNames[i]
+	}
+	return ""
+}
+
+// selectedIDs return the ids of selected items on current page
+func (m model) selectedIDs() map[string]struct{} {
+	ids := make(map[string]struct{})
+	for i := range m.selected {
+		ids[m.itemID(i)] = struct{}{}
+	}
+	return ids
+}
+
+// restoreSelection point the cursor & selection back to the same items
+// after the list has been reordered, cursor stay put if its item is gone
+func (m *model) restoreSelection(cursorID string, selectedIDs map[string]struct{}) {
+	m.selected = make(map[int]struct{})
+	for i := 0; i < getCurrentViewItemCount(*m); i++ {
+		id := m.itemID(i)
+		if id == cursorID && cursorID != "" {
+			m.cursor = i
+		}
+		if _, ok := selectedIDs[id]; ok {
+			m.selected[i] = struct{}{}
+		}
+	}
+	if itemCount := getCurrentViewItemCount(*m); m.cursor >= itemCount {
+		m.cursor = itemCount - 1
+	}
+	m.extendVisual()
+}
+
+// setSort change the sort of the current page, keep the cursor on the same
+// item and persist it to the user config
+func (m *model) setSort(s sortOrder) {
+	cursorID, selectedIDs := m.itemID(m.cursor), m.selectedIDs()
+	m.sorts[m.page] = s
+	m.applySort()
+	m.restoreSelection(cursorID, selectedIDs)
+
+	if m.config.Sort == nil {
+		m.config.Sort = make(map[string]sortConfig)
+	}
+	m.config.Sort[pageNames[m.page]] = sortConfig{Field: s.field, Desc: s.desc}
+	if err := saveConfig(m.config); err != nil {
+		m.logs = "🚧 Failed to save config: " + err.Error() + "\n"
+	}
+}
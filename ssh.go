@@ -0,0 +1,299 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/kevinburke/ssh_config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	defaultRemoteSocket = "/var/run/docker.sock"
+	sshDialTimeout      = 10 * time.Second
+	sshKeepAlive        = 15 * time.Second
+	sshRetries          = 2
+)
+
+// sshTarget is a ssh://[user@]host[:port][/socket] target, host may be an
+// alias of ~/.ssh/config
+type sshTarget struct {
+	alias  string
+	user   string
+	addr   string // host:port to dial
+	socket string // docker socket on the remote host
+}
+
+func parseSSHTarget(endpoint string) (sshTarget, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return sshTarget{}, err
+	}
+	if u.Scheme != "ssh" || u.Hostname() == "" {
+		return sshTarget{}, fmt.Errorf("invalid ssh target %q, want ssh://[user@]host[:port]", endpoint)
+	}
+
+	alias := u.Hostname()
+	t := sshTarget{alias: alias, user: u.User.Username(), socket: u.Path}
+	if t.socket == "" || t.socket == "/" {
+		t.socket = defaultRemoteSocket
+	}
+	if t.user == "" {
+		t.user = sshConfig.Get(alias, "User")
+	}
+	if t.user == "" {
+		if current, err := user.Current(); err == nil {
+			t.user = current.Username
+		}
+	}
+	host := sshConfig.Get(alias, "HostName")
+	if host == "" {
+		host = alias
+	}
+	port := u.Port()
+	if port == "" {
+		port = sshConfig.Get(alias, "Port")
+	}
+	if port == "" {
+		port = "22"
+	}
+	t.addr = net.JoinHostPort(host, port)
+	return t, nil
+}
+
+// remoteHost return user@host of an ssh endpoint, empty if not ssh
+func remoteHost() string {
+	if !strings.HasPrefix(endpoint, "ssh://") {
+		return ""
+	}
+	host, _, _ := strings.Cut(strings.TrimPrefix(endpoint, "ssh://"), "/")
+	return host
+}
+
+// sshAuth use the agent if any, then the unencrypted identity files, the
+// agent connection is needed until the handshake is done
+func sshAuth(alias string) ([]ssh.AuthMethod, io.Closer) {
+	methods := []ssh.AuthMethod{}
+	var agentConn io.Closer = io.NopCloser(nil)
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentConn = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	files := append(sshConfig.GetAll(alias, "IdentityFile"),
+		"~/.ssh/id_ed25519", "~/.ssh/id_ecdsa", "~/.ssh/id_rsa")
+	signers := []ssh.Signer{}
+	seen := map[string]bool{}
+	for _, f := range files {
+		f = expandHome(f)
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		b, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		// keys with a passphrase are expected to be in the agent
+		if signer, err := ssh.ParsePrivateKey(b); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods, agentConn
+}
+
+// knownHostKeyAlgorithms return the key types known_hosts has for addr, so
+// the server is asked for a key that can be verified
+func knownHostKeyAlgorithms(check ssh.HostKeyCallback, addr string) []string {
+	tcpAddr := &net.TCPAddr{IP: net.IPv4zero}
+	var keyErr *knownhosts.KeyError
+	// a key that can't be known make the callback list the known ones
+	if err := check(addr, tcpAddr, placeholderKey{}); !errors.As(err, &keyErr) {
+		return nil
+	}
+	var algorithms []string // nil for unknown hosts, to fail on the host key
+	for _, k := range keyErr.Want {
+		switch k.Key.Type() {
+		case ssh.KeyAlgoRSA:
+			algorithms = append(algorithms, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algorithms = append(algorithms, k.Key.Type())
+		}
+	}
+	return algorithms
+}
+
+type placeholderKey struct{}
+
+func (placeholderKey) Type() string    { return "placeholder" }
+func (placeholderKey) Marshal() []byte { return []byte("placeholder") }
+func (placeholderKey) Verify([]byte, *ssh.Signature) error {
+	return errors.New("placeholder key")
+}
+
+func sshClientConfig(t sshTarget) (*ssh.ClientConfig, error) {
+	files := []string{}
+	for _, f := range strings.Fields(sshConfig.Get(t.alias, "UserKnownHostsFile")) {
+		if _, err := os.Stat(expandHome(f)); err == nil {
+			files = append(files, expandHome(f))
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no known_hosts file, ssh to %s once to trust its host key", t.alias)
+	}
+	check, err := knownhosts.New(files...)
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User: t.user,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			err := check(hostname, remote, key)
+			var keyErr *knownhosts.KeyError
+			if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+				return fmt.Errorf("unknown host key, ssh to %s once to trust it", t.alias)
+			}
+			return err
+		},
+		HostKeyAlgorithms: knownHostKeyAlgorithms(check, t.addr),
+		Timeout:           sshDialTimeout,
+	}, nil
+}
+
+// sshDialer dial the remote docker socket through one ssh connection, it
+// is reopened on the next dial when it drops
+type sshDialer struct {
+	target sshTarget
+	mu     sync.Mutex
+	conn   *ssh.Client
+}
+
+// sshConfigGetter read ssh_config values of a host alias, with defaults
+type sshConfigGetter interface {
+	Get(alias, key string) string
+	GetAll(alias, key string) []string
+}
+
+var (
+	// ~/.ssh/config & /etc/ssh/ssh_config, defaults are used for what can't
+	// be parsed (e.g. Match)
+	sshConfig sshConfigGetter = &ssh_config.UserSettings{IgnoreErrors: true}
+
+	sshDialersMu sync.Mutex
+	sshDialers   = map[string]*sshDialer{} // one connection per target
+)
+
+// newSSHClient return a client talking to the docker socket of an ssh
+// target, the ssh connection is shared by all clients of the target
+func newSSHClient(endpoint string) (*docker.Client, error) {
+	sshDialersMu.Lock()
+	d, ok := sshDialers[endpoint]
+	if !ok {
+		t, err := parseSSHTarget(endpoint)
+		if err != nil {
+			sshDialersMu.Unlock()
+			return nil, err
+		}
+		d = &sshDialer{target: t}
+		sshDialers[endpoint] = d
+	}
+	sshDialersMu.Unlock()
+
+	// a unix client, whose socket is dialed over ssh
+	c, err := docker.NewClient("unix://" + d.target.socket)
+	if err != nil {
+		return nil, err
+	}
+	c.Dialer = d
+	return c, nil
+}
+
+func (d *sshDialer) connect() (*ssh.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn != nil {
+		return d.conn, nil
+	}
+	cfg, err := sshClientConfig(d.target)
+	if err != nil {
+		return nil, err
+	}
+	var agentConn io.Closer
+	cfg.Auth, agentConn = sshAuth(d.target.alias)
+	defer agentConn.Close()
+	conn, err := ssh.Dial("tcp", d.target.addr, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", d.target.addr, err)
+	}
+	d.conn = conn
+	go d.keepAlive(conn)
+	return conn, nil
+}
+
+// drop forget conn, unless it was already replaced
+func (d *sshDialer) drop(conn *ssh.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == conn {
+		d.conn = nil
+	}
+	conn.Close()
+}
+
+// keepAlive detect a dead connection before a request hang on it
+func (d *sshDialer) keepAlive(conn *ssh.Client) {
+	ticker := time.NewTicker(sshKeepAlive)
+	defer ticker.Stop()
+	closed := make(chan struct{})
+	go func() {
+		conn.Wait()
+		close(closed)
+	}()
+	for {
+		select {
+		case <-closed:
+			d.drop(conn)
+			return
+		case <-ticker.C:
+			if _, _, err := conn.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+				d.drop(conn)
+				return
+			}
+		}
+	}
+}
+
+// Dial open a stream to the remote socket, network & address of the local
+// client are ignored
+func (d *sshDialer) Dial(network, address string) (net.Conn, error) {
+	var err error
+	for i := 0; i < sshRetries; i++ {
+		var conn *ssh.Client
+		if conn, err = d.connect(); err != nil {
+			continue
+		}
+		var stream net.Conn
+		if stream, err = conn.Dial("unix", d.target.socket); err == nil {
+			return stream, nil
+		}
+		// the connection may be dead without knowing it yet
+		d.drop(conn)
+	}
+	return nil, err
+}
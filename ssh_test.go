@@ -0,0 +1,343 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/kevinburke/ssh_config"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeSSHConfig is an ssh_config file with the defaults of UserSettings
+type fakeSSHConfig struct {
+	cfg *ssh_config.Config
+}
+
+func (f fakeSSHConfig) Get(alias, key string) string {
+	if v, _ := f.cfg.Get(alias, key); v != "" {
+		return v
+	}
+	return ssh_config.Default(key)
+}
+
+func (f fakeSSHConfig) GetAll(alias, key string) []string {
+	v, _ := f.cfg.GetAll(alias, key)
+	return v
+}
+
+// useSSHConfig replace the ssh config for the test
+func useSSHConfig(t *testing.T, text string) {
+	t.Helper()
+	cfg, err := ssh_config.Decode(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := sshConfig
+	sshConfig = fakeSSHConfig{cfg: cfg}
+	t.Cleanup(func() { sshConfig = old })
+}
+
+func TestParseSSHTarget(t *testing.T) {
+	useSSHConfig(t, `
+Host staging
+  HostName 10.0.0.5
+  User deploy
+  Port 2200
+
+Host *.internal
+  User ops
+`)
+	current := ""
+	if u, err := user.Current(); err == nil {
+		current = u.Username
+	}
+
+	tests := []struct {
+		endpoint string
+		want     sshTarget
+		err      bool
+	}{
+		{"ssh://me@host", sshTarget{alias: "host", user: "me", addr: "host:22", socket: defaultRemoteSocket}, false},
+		{"ssh://me@host:2222", sshTarget{alias: "host", user: "me", addr: "host:2222", socket: defaultRemoteSocket}, false},
+		{"ssh://host", sshTarget{alias: "host", user: current, addr: "host:22", socket: defaultRemoteSocket}, false},
+		{"ssh://me@host/run/podman/podman.sock", sshTarget{alias: "host", user: "me", addr: "host:22", socket: "/run/podman/podman.sock"}, false},
+		{"ssh://me@[::1]:2222", sshTarget{alias: "::1", user: "me", addr: "[::1]:2222", socket: defaultRemoteSocket}, false},
+		{"ssh://[fe80::1]", sshTarget{alias: "fe80::1", user: current, addr: "[fe80::1]:22", socket: defaultRemoteSocket}, false},
+		// alias of ~/.ssh/config, the url win over it
+		{"ssh://staging", sshTarget{alias: "staging", user: "deploy", addr: "10.0.0.5:2200", socket: defaultRemoteSocket}, false},
+		{"ssh://root@staging:22", sshTarget{alias: "staging", user: "root", addr: "10.0.0.5:22", socket: defaultRemoteSocket}, false},
+		{"ssh://db.internal", sshTarget{alias: "db.internal", user: "ops", addr: "db.internal:22", socket: defaultRemoteSocket}, false},
+		{"ssh://me@", sshTarget{}, true},
+		{"ssh://", sshTarget{}, true},
+		{"tcp://host:2375", sshTarget{}, true},
+		{"ssh://host:port", sshTarget{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSSHTarget(tt.endpoint)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: err = %v, want error %v", tt.endpoint, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+// sshServer is an in-process ssh server forwarding streamlocal channels to
+// unix sockets, like sshd does for `ssh -L`
+type sshServer struct {
+	addr   string
+	mu     sync.Mutex
+	conns  []net.Conn
+	logins int
+}
+
+func startSSHServer(t *testing.T, hostKey ssh.Signer, clientKey ssh.PublicKey) *sshServer {
+	t.Helper()
+	cfg := &ssh.ServerConfig{
+		PublicKeyCallback: func(c ssh.ConnMetadata, k ssh.PublicKey) (*ssh.Permissions, error) {
+			if c.User() == "whale" && string(k.Marshal()) == string(clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	cfg.AddHostKey(hostKey)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &sshServer{addr: l.Addr().String()}
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, nc)
+			s.mu.Unlock()
+			go s.serve(nc, cfg)
+		}
+	}()
+	return s
+}
+
+func (s *sshServer) serve(nc net.Conn, cfg *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(nc, cfg)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	s.logins++
+	s.mu.Unlock()
+	go func() {
+		for r := range reqs {
+			r.Reply(r.Type == "keepalive@openssh.com", nil)
+		}
+	}()
+	for nch := range chans {
+		var target struct {
+			Path      string
+			Reserved0 string
+			Reserved1 uint32
+		}
+		if nch.ChannelType() != "direct-streamlocal@openssh.com" || ssh.Unmarshal(nch.ExtraData(), &target) != nil {
+			nch.Reject(ssh.Prohibited, "only streamlocal")
+			continue
+		}
+		uc, err := net.Dial("unix", target.Path)
+		if err != nil {
+			nch.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		ch, creqs, err := nch.Accept()
+		if err != nil {
+			uc.Close()
+			continue
+		}
+		go ssh.DiscardRequests(creqs)
+		go func() {
+			io.Copy(ch, uc)
+			ch.CloseWrite()
+			ch.Close()
+		}()
+		go func() {
+			io.Copy(uc, ch)
+			uc.Close()
+		}()
+	}
+}
+
+// dropAll close every connection server side, like sshd restarting
+func (s *sshServer) dropAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, nc := range s.conns {
+		nc.Close()
+	}
+	s.conns = nil
+}
+
+func (s *sshServer) loginCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.logins
+}
+
+// fakeDaemon serve a docker API listing one container on a unix socket
+func fakeDaemon(t *testing.T, dir string) string {
+	t.Helper()
+	sock := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/containers/json") {
+			w.Write([]byte(`[{"Id":"abc","Names":["/web"],"State":"running"}]`))
+			return
+		}
+		w.Write([]byte("OK"))
+	})}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return sock
+}
+
+func TestSSHDialerReconnect(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir) // no default identity files
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, _ := ssh.NewSignerFromKey(hostPriv)
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	block, err := ssh.MarshalPrivateKey(clientPriv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(identity, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	sshClientPub, _ := ssh.NewPublicKey(clientPub)
+
+	server := startSSHServer(t, hostKey, sshClientPub)
+	knownHosts := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(server.addr)}, hostKey.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	host, port, _ := net.SplitHostPort(server.addr)
+	useSSHConfig(t, `
+Host box
+  HostName `+host+`
+  Port `+port+`
+  User whale
+  IdentityFile `+identity+`
+  UserKnownHostsFile `+knownHosts+`
+`)
+	sock := fakeDaemon(t, dir)
+
+	client, err := newSSHClient("ssh://box" + sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sshDialersMu.Lock()
+		delete(sshDialers, "ssh://box"+sock)
+		sshDialersMu.Unlock()
+	})
+	list := func() {
+		t.Helper()
+		containers, err := client.ListContainers(docker.ListContainersOptions{All: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(containers) != 1 || apiContainerName(containers[0]) != "web" {
+			t.Fatalf("containers = %+v", containers)
+		}
+	}
+
+	list()
+	if n := server.loginCount(); n != 1 {
+		t.Fatalf("logins = %d, want 1", n)
+	}
+	list() // same connection
+	if n := server.loginCount(); n != 1 {
+		t.Fatalf("logins = %d after second request, want 1", n)
+	}
+
+	// dropped by the client, e.g. keepalive failed
+	sshDialersMu.Lock()
+	d := sshDialers["ssh://box"+sock]
+	sshDialersMu.Unlock()
+	d.mu.Lock()
+	conn := d.conn
+	d.mu.Unlock()
+	d.drop(conn)
+	client.HTTPClient.CloseIdleConnections()
+	list()
+	if n := server.loginCount(); n != 2 {
+		t.Fatalf("logins = %d after drop, want 2", n)
+	}
+
+	// dropped by the server, the dialer notice and reconnect
+	server.dropAll()
+	client.HTTPClient.CloseIdleConnections()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		d.mu.Lock()
+		gone := d.conn == nil
+		d.mu.Unlock()
+		if gone || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	list()
+	if n := server.loginCount(); n != 3 {
+		t.Fatalf("logins = %d after server drop, want 3", n)
+	}
+}
+
+func TestSSHDialerUnknownHost(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("SSH_AUTH_SOCK", "")
+
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, _ := ssh.NewSignerFromKey(hostPriv)
+	clientPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	sshClientPub, _ := ssh.NewPublicKey(clientPub)
+	server := startSSHServer(t, hostKey, sshClientPub)
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	if err := os.WriteFile(knownHosts, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	useSSHConfig(t, "Host *\n  UserKnownHostsFile "+knownHosts+"\n")
+
+	d := &sshDialer{target: sshTarget{alias: "box", user: "whale", addr: server.addr, socket: defaultRemoteSocket}}
+	_, err := d.Dial("unix", defaultRemoteSocket)
+	if err == nil || !strings.Contains(err.Error(), "unknown host key") {
+		t.Fatalf("err = %v, want unknown host key", err)
+	}
+}
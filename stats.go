@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// stats are expensive (docker samples twice per request), poll much slower
+// than the tick rate
+const statsRate = 5 * time.Second
+
+type containerStats struct {
+	cpuPercent float64
+	memUsage   uint64
+	memLimit   uint64
+}
+
+type StatsMsg struct {
+	stats map[string]containerStats // map[containerID]containerStats
+}
+
+func calculateCPUPercent(s *docker.Stats) float64 {
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(s.CPUStats.SystemCPUUsage) - float64(s.PreCPUStats.SystemCPUUsage)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+	cpus := float64(s.CPUStats.OnlineCPUs)
+	if cpus == 0 {
+		cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+	}
+	return cpuDelta / systemDelta * cpus * 100
+}
+
+// fetchStats take a single stats sample of the given container
+func fetchStats(c *docker.Client, id string) (containerStats, error) {
+	ch := make(chan *docker.Stats, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Stats(docker.StatsOptions{
+			ID:      id,
+			Stats:   ch,
+			Stream:  false,
+			Timeout: statsRate,
+		})
+	}()
+
+	s, ok := <-ch
+	if !ok || s == nil {
+		return containerStats{}, <-errCh
+	}
+	return containerStats{
+		cpuPercent: calculateCPUPercent(s),
+		memUsage:   s.MemoryStats.Usage,
+		memLimit:   s.MemoryStats.Limit,
+	}, nil
+}
+
+func doStatsTick() tea.Cmd {
+	return tea.Tick(statsRate, func(time.Time) tea.Msg {
+		return collectStats()
+	})
+}
+
+// collectStats sample stats of every running container concurrently
+func collectStats() tea.Msg {
+	client, err := newClient()
+	if err != nil {
+		return StatsMsg{}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	stats := make(map[string]containerStats)
+	for _, c := range listContainers(client, false) {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			s, err := fetchStats(client, id)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			stats[id] = s
+			mu.Unlock()
+		}(c.ID)
+	}
+	wg.Wait()
+	return StatsMsg{stats: stats}
+}
+
+func formatStats(s containerStats) string {
+	return fmt.Sprintf(
+		"%.2f%% cpu, %s / %s",
+		s.cpuPercent,
+		convertSizeToHumanRedable(int64(s.memUsage)),
+		convertSizeToHumanRedable(int64(s.memLimit)),
+	)
+}
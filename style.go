@@ -2,6 +2,7 @@ package main
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	docker "github.com/fsouza/go-dockerclient"
 )
 
 const (
@@ -48,6 +49,7 @@ const (
 	fixedContentWidth     = fullWidth - fixedPadLR // 86
 	maxContainerNameWidth = 22
 	maxImageNameWidth     = 24
+	imageNameColumnWidth  = 12 // name column when size, age & usage are shown
 	maxVolumeNameWidth    = 22
 	prefixWidth           = 6
 	fixedBodyLWidth       = prefixWidth + maxContainerNameWidth // 28 exclude padding
@@ -64,6 +66,59 @@ var (
 		"dead":       lipgloss.NewStyle().Foreground(black),
 	}
 
+	// health overrides the running state color
+	healthStyleMap = map[string]lipgloss.Style{
+		healthStarting:  lipgloss.NewStyle().Foreground(yellow),
+		healthHealthy:   lipgloss.NewStyle().Foreground(hotGreen),
+		healthUnhealthy: lipgloss.NewStyle().Foreground(red).Bold(true),
+	}
+
+	notUndoableStyle = lipgloss.NewStyle().Foreground(paletteA1)
+
+	exitCodeStyle = lipgloss.NewStyle().Foreground(paletteA1)
+
+	unhealthyStyle = lipgloss.NewStyle().Foreground(red).Bold(true)
+
+	changeStyleMap = map[docker.ChangeType]lipgloss.Style{
+		docker.ChangeAdd:    lipgloss.NewStyle().Foreground(hotGreen),
+		docker.ChangeModify: lipgloss.NewStyle().Foreground(orange),
+		docker.ChangeDelete: lipgloss.NewStyle().Foreground(red),
+	}
+
+	drillStyle = lipgloss.NewStyle().
+			Padding(1, 4, 0, 4).
+			Width(fullWidth - 2)
+
+	topHeaderStyle = lipgloss.NewStyle().
+			Foreground(celesBlue).
+			Bold(true)
+
+	fileDirStyle = lipgloss.NewStyle().
+			Foreground(celesBlue).
+			Bold(true)
+
+	fileLinkStyle = lipgloss.NewStyle().
+			Foreground(midPurple)
+
+	matchStyle = lipgloss.NewStyle().
+			Foreground(orange).
+			Bold(true)
+
+	graphGroupStyle = lipgloss.NewStyle().
+			Foreground(grey).
+			Italic(true)
+
+	formErrorStyle = lipgloss.NewStyle().
+			Foreground(red)
+
+	committedImageStyle = lipgloss.NewStyle().
+				Foreground(hotGreen).
+				Bold(true)
+
+	drillTitleStyle = lipgloss.NewStyle().
+			Bold(true).
+			MarginBottom(1)
+
 	bodyLStyle = lipgloss.NewStyle().
 			Padding(1, 0, 0, 4).
 			BorderForeground(black)
@@ -86,6 +141,47 @@ var (
 	logStyle = lipgloss.NewStyle().
 			Foreground(black)
 
+	toastStyle = lipgloss.NewStyle().
+			PaddingLeft(4)
+
+	toastTitleStyle = lipgloss.NewStyle().
+			Foreground(orange).
+			Bold(true)
+
+	toastLineStyle = lipgloss.NewStyle().
+			Foreground(grey)
+
+	outputHeaderStyle = lipgloss.NewStyle().
+				Foreground(celesBlue).
+				Bold(true)
+
+	readOnlyStyle = lipgloss.NewStyle().
+			Foreground(pitchBlack).
+			Background(red).
+			Bold(true).
+			Padding(0, 1)
+
+	dryRunStyle = lipgloss.NewStyle().
+			Foreground(yellow).
+			Bold(true)
+
+	dryRunCommandStyle = lipgloss.NewStyle().
+				Foreground(yellow)
+
+	pinStyle = lipgloss.NewStyle().
+			Foreground(orange)
+
+	watchStyle = lipgloss.NewStyle().
+			Foreground(lightBlue)
+
+	statusStyle = lipgloss.NewStyle().
+			Foreground(grey).
+			PaddingLeft(4)
+
+	visualStyle = lipgloss.NewStyle().
+			Foreground(midPink).
+			Bold(true)
+
 	checkStyle = lipgloss.NewStyle().
 			Foreground(hotGreen)
 
@@ -93,10 +189,24 @@ var (
 			Foreground(frenchBlue).
 			Bold(true)
 
+	tabStyle = lipgloss.NewStyle().
+			Foreground(grey)
+
+	activeTabStyle = lipgloss.NewStyle().
+			Foreground(celesBlue).
+			Underline(true)
+
+	sortStyle = lipgloss.NewStyle().
+			Foreground(grey).
+			Bold(false)
+
 	PortMapColStyle = lipgloss.NewStyle().Foreground(paletteA10)
 
 	inUseTextTrueStyle  = lipgloss.NewStyle().Foreground(green).Bold(true)
 	inUseTextFalseStyle = lipgloss.NewStyle().Foreground(paletteA2)
 	inUseIconTrueStyle  = lipgloss.NewStyle().Foreground(paletteA7)
 	inUseIconFalseStyle = lipgloss.NewStyle()
+
+	unusedImageStyle   = lipgloss.NewStyle().Foreground(grey)
+	danglingImageStyle = lipgloss.NewStyle().Foreground(grey).Faint(true).Italic(true)
 )
@@ -0,0 +1,551 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mattn/go-runewidth"
+)
+
+// disk usage categories, the rows of system page
+const (
+	dfImages int = iota
+	dfContainers
+	dfVolumes
+	dfBuildCache
+)
+
+var dfCategoryNames = []string{"Images", "Containers", "Volumes", "Build cache"}
+
+// prefilled prune filters unless set in config
+const defaultPruneFilters = "until=7d label!=keep"
+
+type dfVolume struct {
+	docker.Volume
+	UsageData *docker.VolumeUsageData
+}
+
+type buildCacheRecord struct {
+	ID          string
+	Type        string
+	Description string
+	InUse       bool
+	Shared      bool
+	Size        int64
+	CreatedAt   time.Time
+	LastUsedAt  *time.Time
+}
+
+// diskUsage is the response of system df, go-dockerclient's DiskUsage lack
+// build cache & volume sizes
+type diskUsage struct {
+	LayersSize int64
+	Images     []*docker.ImageSummary
+	Containers []*docker.APIContainers
+	Volumes    []*dfVolume
+	BuildCache []*buildCacheRecord
+}
+
+type dfSummary struct {
+	count       int
+	active      int
+	size        int64
+	reclaimable int64
+}
+
+// systemView is the state of system page
+type systemView struct {
+	usage        *diskUsage
+	err          error
+	loading      bool
+	pruneBefore  int64 // total size before the running prune
+	prunePending int   // prune batches not done yet
+}
+
+type DiskUsageMsg struct {
+	usage *diskUsage
+	err   error
+}
+
+type BuildPruneMsg struct {
+	entry     int // history entry
+	reclaimed int64
+	err       error
+}
+
+func fetchDiskUsage() tea.Msg {
+	client, err := newClient()
+	if err != nil {
+		return DiskUsageMsg{err: err}
+	}
+	var du diskUsage
+	if err := apiRequest(client, http.MethodGet, "/system/df", nil, &du); err != nil {
+		return DiskUsageMsg{err: err}
+	}
+	return DiskUsageMsg{usage: &du}
+}
+
+// pruneBuildCache prune the build cache records with the given ids
+func pruneBuildCache(entry int, ids []string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return BuildPruneMsg{entry: entry, err: err}
+		}
+		filters, _ := json.Marshal(map[string][]string{"id": ids})
+		var res struct{ SpaceReclaimed int64 }
+		path := "/build/prune?filters=" + url.QueryEscape(string(filters))
+		err = apiRequest(client, http.MethodPost, path, nil, &res)
+		return BuildPruneMsg{entry: entry, reclaimed: res.SpaceReclaimed, err: err}
+	}
+}
+
+func isDanglingSummary(img *docker.ImageSummary) bool {
+	return len(img.RepoTags) == 0 || (len(img.RepoTags) == 1 && img.RepoTags[0] == "<none>:<none>")
+}
+
+// summary is what `docker system df` show for a category
+func (du diskUsage) summary(category int) dfSummary {
+	var s dfSummary
+	switch category {
+	case dfImages:
+		s.count = len(du.Images)
+		s.size = du.LayersSize
+		for _, img := range du.Images {
+			if img.Containers > 0 {
+				s.active++
+			} else {
+				s.reclaimable += img.Size - img.SharedSize
+			}
+		}
+	case dfContainers:
+		s.count = len(du.Containers)
+		for _, c := range du.Containers {
+			s.size += c.SizeRw
+			if c.State == "running" {
+				s.active++
+			} else {
+				s.reclaimable += c.SizeRw
+			}
+		}
+	case dfVolumes:
+		s.count = len(du.Volumes)
+		for _, v := range du.Volumes {
+			if v.UsageData == nil || v.UsageData.Size < 0 {
+				continue // not available for this driver
+			}
+			s.size += v.UsageData.Size
+			if v.UsageData.RefCount > 0 {
+				s.active++
+			} else {
+				s.reclaimable += v.UsageData.Size
+			}
+		}
+	case dfBuildCache:
+		s.count = len(du.BuildCache)
+		for _, b := range du.BuildCache {
+			if b.Shared {
+				continue
+			}
+			s.size += b.Size
+			if b.InUse {
+				s.active++
+			} else {
+				s.reclaimable += b.Size
+			}
+		}
+	}
+	if s.reclaimable > s.size {
+		s.reclaimable = s.size
+	}
+	return s
+}
+
+func (du diskUsage) totalSize() int64 {
+	var total int64
+	for category := range dfCategoryNames {
+		total += du.summary(category).size
+	}
+	return total
+}
+
+// ----------------------------- prune -----------------------------
+
+// labelFilter is label=key, label=key=value or their != variants
+type labelFilter struct {
+	key      string
+	value    string
+	hasValue bool
+	negate   bool
+}
+
+// pruneFilter is the parsed filters of the prune wizard, same syntax as
+// `docker system prune --filter` except until also accept days e.g. 7d
+type pruneFilter struct {
+	until  time.Duration
+	labels []labelFilter
+}
+
+func parseDuration(s string) (time.Duration, error) {
+	if strings.HasSuffix(s, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+func parsePruneFilters(s string) (pruneFilter, error) {
+	var f pruneFilter
+	for _, field := range strings.Fields(s) {
+		switch {
+		case strings.HasPrefix(field, "until="):
+			d, err := parseDuration(strings.TrimPrefix(field, "until="))
+			if err != nil {
+				return f, err
+			}
+			f.until = d
+		case strings.HasPrefix(field, "label="), strings.HasPrefix(field, "label!="):
+			l := labelFilter{negate: strings.HasPrefix(field, "label!=")}
+			kv := strings.TrimPrefix(strings.TrimPrefix(field, "label="), "label!=")
+			l.key, l.value, l.hasValue = strings.Cut(kv, "=")
+			f.labels = append(f.labels, l)
+		default:
+			return f, fmt.Errorf("unknown filter %q, use until=<duration> or label[!]=<key>[=<value>]", field)
+		}
+	}
+	return f, nil
+}
+
+func (f pruneFilter) match(created time.Time, labels map[string]string) bool {
+	if f.until > 0 && created.After(time.Now().Add(-f.until)) {
+		return false
+	}
+	for _, l := range f.labels {
+		value, ok := labels[l.key]
+		has := ok && (!l.hasValue || value == l.value)
+		if has == l.negate {
+			return false
+		}
+	}
+	return true
+}
+
+func (f pruneFilter) String() string {
+	parts := []string{}
+	if f.until > 0 {
+		parts = append(parts, "older than "+formatDuration(f.until))
+	}
+	for _, l := range f.labels {
+		op := "="
+		if l.negate {
+			op = "!="
+		}
+		s := "label" + op + l.key
+		if l.hasValue {
+			s += "=" + l.value
+		}
+		parts = append(parts, s)
+	}
+	if len(parts) == 0 {
+		return "no filters"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	return d.String()
+}
+
+type pruneTarget struct {
+	category int
+	id       string
+	name     string
+	size     int64
+}
+
+// pruneWizard is the preview of a prune, nothing is deleted until confirmed
+type pruneWizard struct {
+	filter  pruneFilter
+	targets []pruneTarget
+}
+
+// pruneTargets list exactly what a prune of the categories would delete:
+// stopped containers, dangling images, unused volumes & build cache
+func pruneTargets(du diskUsage, categories []int, f pruneFilter) []pruneTarget {
+	targets := []pruneTarget{}
+	for _, category := range categories {
+		switch category {
+		case dfContainers:
+			for _, c := range du.Containers {
+				if c.State == "running" || c.State == "paused" || c.State == "restarting" {
+					continue
+				}
+				if !f.match(time.Unix(c.Created, 0), c.Labels) {
+					continue
+				}
+				name := c.ID
+				if len(c.Names) > 0 {
+					name = strings.TrimPrefix(c.Names[0], "/")
+				}
+				targets = append(targets, pruneTarget{category, c.ID, name, c.SizeRw})
+			}
+		case dfImages:
+			for _, img := range du.Images {
+				if !isDanglingSummary(img) || img.Containers > 0 {
+					continue
+				}
+				if !f.match(time.Unix(img.Created, 0), img.Labels) {
+					continue
+				}
+				targets = append(targets, pruneTarget{category, img.ID, shortID(img.ID), img.Size - img.SharedSize})
+			}
+		case dfVolumes:
+			for _, v := range du.Volumes {
+				if v.UsageData == nil || v.UsageData.RefCount != 0 {
+					continue
+				}
+				if !f.match(v.CreatedAt, v.Labels) {
+					continue
+				}
+				size := v.UsageData.Size
+				if size < 0 {
+					size = 0
+				}
+				targets = append(targets, pruneTarget{category, v.Name, v.Name, size})
+			}
+		case dfBuildCache:
+			for _, b := range du.BuildCache {
+				if b.InUse {
+					continue
+				}
+				lastUsed := b.CreatedAt
+				if b.LastUsedAt != nil {
+					lastUsed = *b.LastUsedAt
+				}
+				// build cache has no labels
+				if !f.match(lastUsed, nil) {
+					continue
+				}
+				name := b.Description
+				if name == "" {
+					name = b.Type
+				}
+				targets = append(targets, pruneTarget{category, b.ID, name, b.Size})
+			}
+		}
+	}
+	return targets
+}
+
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// selectedCategories return the selected rows of system page, the one
+// under cursor if none
+func (m model) selectedCategories() []int {
+	categories := []int{}
+	for category := range dfCategoryNames {
+		if _, ok := m.selected[category]; ok {
+			categories = append(categories, category)
+		}
+	}
+	if len(categories) == 0 && m.cursor >= 0 {
+		categories = append(categories, m.cursor)
+	}
+	return categories
+}
+
+// openPruneWizard ask for filters then preview what would be deleted
+func openPruneWizard(m model) (tea.Model, tea.Cmd) {
+	if m.system.usage == nil {
+		m.logs = "🚧 Disk usage is not loaded yet...\n"
+		return m, nil
+	}
+	if m.system.prunePending > 0 {
+		m.logs = "🚧 A prune is still running...\n"
+		return m, nil
+	}
+
+	categories := m.selectedCategories()
+	filters := m.config.PruneFilters
+	if filters == "" {
+		filters = defaultPruneFilters
+	}
+	m.openPrompt("prune filters", filters, func(m model, value string) (model, tea.Cmd) {
+		f, err := parsePruneFilters(value)
+		if err != nil {
+			m.logs = "🚧 " + err.Error() + "\n"
+			return m, nil
+		}
+		m.prune = pruneWizard{
+			filter:  f,
+			targets: pruneTargets(*m.system.usage, categories, f),
+		}
+		m.openDrill(drillPrune)
+		return m, nil
+	})
+	return m, nil
+}
+
+// confirmPrune delete the previewed targets, objects are removed one by one
+// so the preview is exactly what get deleted
+func confirmPrune(m model) (tea.Model, tea.Cmd) {
+	jobs := []job{}
+	cacheIDs := []string{}
+	caches := []historyTarget{}
+	ops := map[int]string{dfContainers: opRemoveContainer, dfImages: opRemoveImage, dfVolumes: opRemoveVolume}
+	for _, t := range m.prune.targets {
+		if t.category == dfBuildCache {
+			cacheIDs = append(cacheIDs, t.id)
+			caches = append(caches, historyTarget{ID: t.id, Name: t.name})
+			continue
+		}
+		jobs = append(jobs, job{id: t.id, name: t.name, op: ops[t.category]})
+		addProcess(&m, t.id, t.name, "pruning", "x")
+	}
+	m.closeDrill()
+	if len(jobs) == 0 && len(cacheIDs) == 0 {
+		m.logs = "🧹 Nothing to prune\n"
+		return m, nil
+	}
+
+	if m.dryRunMode {
+		commands := m.jobCommands(jobs)
+		for _, id := range cacheIDs {
+			commands = append(commands, buildCacheCommand(id))
+		}
+		m.dryRun("pruning", commands)
+		return m, nil
+	}
+
+	m.system.pruneBefore = m.system.usage.totalSize()
+	m.system.prunePending = 0
+	var cmd tea.Cmd
+	if len(jobs) > 0 {
+		m.submit("pruning", jobs)
+		m.system.prunePending++
+	}
+	if len(cacheIDs) > 0 {
+		entry := m.record("pruning build cache", caches...)
+		cmd = pruneBuildCache(entry, cacheIDs)
+		m.system.prunePending++
+	}
+	m.logs = fmt.Sprintf(
+		"🧹 Pruning %v object(s), this can't be undone\n",
+		itemCountStyle.Render(fmt.Sprintf("%d", len(m.prune.targets))))
+	m.clearSelection()
+	return m, cmd
+}
+
+// finishPrune count down a finished prune batch, disk usage is refreshed
+// once all are done to report what was reclaimed
+func (m *model) finishPrune() tea.Cmd {
+	if m.system.prunePending == 0 {
+		return nil
+	}
+	m.system.prunePending--
+	if m.system.prunePending > 0 {
+		return nil
+	}
+	return fetchDiskUsage
+}
+
+func handleSystemKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Clean): // prune wizard
+		return openPruneWizard(m)
+	default:
+		return handleCommonKeys(&m, msg)
+	}
+}
+
+func handlePruneKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Confirm) {
+		return confirmPrune(m)
+	}
+	return m, nil
+}
+
+func buildPruneView(m model) (string, string) {
+	p := m.prune
+	var total int64
+	for _, t := range p.targets {
+		total += t.size
+	}
+	title := fmt.Sprintf("Prune preview: %d object(s), %s", len(p.targets), convertSizeToHumanRedable(total)) +
+		sortStyle.Render("  ("+p.filter.String()+")")
+	if len(p.targets) == 0 {
+		return title, "Nothing matches, esc to go back."
+	}
+
+	lines := []string{}
+	for _, t := range p.targets {
+		name := runewidth.Truncate(t.name, fullWidth-40, "…")
+		lines = append(lines, fmt.Sprintf("%-12s %10s  %s", dfCategoryNames[t.category], convertSizeToHumanRedable(t.size), name))
+	}
+	return title, renderRows(lines, m.drillCursor, m.drillOffset, drillHeight(m))
+}
+
+// ----------------------------- view -----------------------------
+
+func buildSystemDesc(m model) string {
+	if m.system.err != nil {
+		return "🚧 " + m.system.err.Error()
+	}
+	if m.system.usage == nil {
+		return "Loading..."
+	}
+	s := m.system.usage.summary(m.cursor)
+	desc := fmt.Sprintf("Total       : %d\n", s.count)
+	desc += fmt.Sprintf("Active      : %d\n", s.active)
+	desc += fmt.Sprintf("Size        : %s\n", convertSizeToHumanRedable(s.size))
+	desc += fmt.Sprintf("Reclaimable : %s", convertSizeToHumanRedable(s.reclaimable))
+	if s.size > 0 {
+		desc += fmt.Sprintf(" (%d%%)", s.reclaimable*100/s.size)
+	}
+	desc += "\n\n"
+	desc += fmt.Sprintf("All disk    : %s\n", convertSizeToHumanRedable(m.system.usage.totalSize()))
+	return desc
+}
+
+func buildSystemView(m model) (string, string) {
+	var bodyL, bodyR string
+	for i, name := range dfCategoryNames {
+		cursor := " "
+		check := " "
+		if m.cursor == i {
+			cursor = "❯"
+			bodyR = buildCursorDesc(m)
+		}
+		if _, ok := m.selected[i]; ok {
+			check = checkStyle.Render("✔")
+		}
+		if m.system.usage != nil {
+			name = fmt.Sprintf("%-12s %s", name, convertSizeToHumanRedable(m.system.usage.summary(i).size))
+		}
+		row := fmt.Sprintf("%s %s %s", cursor, check, padItemName(name, fixedBodyLWidth-4))
+		bodyL += row
+	}
+
+	padBodyHeight(&bodyL, len(dfCategoryNames)+2)
+	return bodyLStyle.Render(bodyL), bodyRStyle.Render(scrollLines(bodyR, m.descOffset))
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSelectAllPruneCategories(t *testing.T) {
+	m := model{page: pageSystem, selected: make(map[int]struct{}), keys: keys}
+	selectAll := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")}
+
+	handleCommonKeys(&m, selectAll)
+	if got := m.selectedCategories(); len(got) != len(dfCategoryNames) {
+		t.Fatalf("categories = %v, want all %d", got, len(dfCategoryNames))
+	}
+
+	// pressed again, clear
+	handleCommonKeys(&m, selectAll)
+	if len(m.selected) != 0 {
+		t.Errorf("selected = %v, want none", m.selected)
+	}
+}
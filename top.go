@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	topRate          = 2 * time.Second
+	defaultTopPsArgs = "aux"
+)
+
+// topColumns are the columns shown, each matched against ps titles
+var topColumns = []struct {
+	name   string
+	titles []string
+	width  int
+}{
+	{name: "pid", titles: []string{"PID"}, width: 7},
+	{name: "user", titles: []string{"USER", "UID"}, width: 9},
+	{name: "cpu", titles: []string{"%CPU", "C"}, width: 6},
+	{name: "mem", titles: []string{"%MEM", "RSS"}, width: 6},
+	{name: "command", titles: []string{"COMMAND", "CMD", "ARGS"}},
+}
+
+// topView is the state of the process list of a running container
+type topView struct {
+	containerID string
+	name        string
+	psArgs      string
+	titles      []string
+	processes   [][]string
+	err         error
+	loading     bool
+	sortColumn  int
+	sortDesc    bool
+}
+
+type TopMsg struct {
+	containerID string
+	result      docker.TopResult
+	err         error
+}
+
+type SignalMsg struct {
+	entry  int // history entry
+	pid    string
+	signal string
+	output string
+	err    error
+}
+
+func fetchTop(id, psArgs string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return TopMsg{containerID: id, err: err}
+		}
+		result, err := client.TopContainer(id, psArgs)
+		return TopMsg{containerID: id, result: result, err: err}
+	}
+}
+
+func doTopTick(id, psArgs string) tea.Cmd {
+	return tea.Tick(topRate, func(time.Time) tea.Msg {
+		return fetchTop(id, psArgs)()
+	})
+}
+
+// sendSignal send signal to pid inside the container using `kill` via exec
+func (m *model) sendSignal(id, name, pid, signal string) tea.Cmd {
+	entry := m.record(fmt.Sprintf("sending SIG%s to pid %s", signal, pid), historyTarget{ID: id, Name: name})
+	return func() tea.Msg {
+		client, err := newClient()
+		if err != nil {
+			return SignalMsg{entry: entry, pid: pid, signal: signal, err: err}
+		}
+		output, code, err := execInContainer(client, id, []string{"kill", "-" + signal, pid})
+		if err == nil && code != 0 {
+			err = fmt.Errorf("kill exited with code %d", code)
+		}
+		return SignalMsg{entry: entry, pid: pid, signal: signal, output: strings.TrimSpace(output), err: err}
+	}
+}
+
+// columnIndex return the index of column in ps titles, -1 if ps args don't
+// include it
+func (t topView) columnIndex(column int) int {
+	for i, title := range t.titles {
+		for _, want := range topColumns[column].titles {
+			if title == want {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (t topView) cell(process []string, column int) string {
+	i := t.columnIndex(column)
+	if i < 0 || i >= len(process) {
+		return ""
+	}
+	return process[i]
+}
+
+// sortedProcesses sort by the sort column, numerically when possible
+func (t topView) sortedProcesses() [][]string {
+	processes := make([][]string, len(t.processes))
+	copy(processes, t.processes)
+	sort.SliceStable(processes, func(i, j int) bool {
+		a, b := t.cell(processes[i], t.sortColumn), t.cell(processes[j], t.sortColumn)
+		var cmp int
+		fa, errA := strconv.ParseFloat(a, 64)
+		fb, errB := strconv.ParseFloat(b, 64)
+		if errA == nil && errB == nil {
+			cmp = compareFloat(fa, fb)
+		} else {
+			cmp = strings.Compare(a, b)
+		}
+		if t.sortDesc {
+			return cmp > 0
+		}
+		return cmp < 0
+	})
+	return processes
+}
+
+func openTop(m model) (tea.Model, tea.Cmd) {
+	c := m.containers[m.cursor]
+	if c.state != "running" {
+		m.logs = "🚧 Can only list processes of running container...\n"
+		return m, nil
+	}
+	psArgs := m.config.TopPsArgs
+	if psArgs == "" {
+		psArgs = defaultTopPsArgs
+	}
+	m.top = topView{
+		containerID: c.id,
+		name:        c.name,
+		psArgs:      psArgs,
+		loading:     true,
+		sortColumn:  2, // cpu
+		sortDesc:    true,
+	}
+	m.openDrill(drillTop)
+	return m, fetchTop(c.id, psArgs)
+}
+
+func handleTopKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Sort): // cycle sort column
+		m.top.sortColumn = (m.top.sortColumn + 1) % len(topColumns)
+	case key.Matches(msg, m.keys.SortOrder): // reverse sort
+		m.top.sortDesc = !m.top.sortDesc
+	case key.Matches(msg, m.keys.Signal): // send signal to process under cursor
+		processes := m.top.sortedProcesses()
+		if m.drillCursor >= len(processes) {
+			return m, nil
+		}
+		pid := m.top.cell(processes[m.drillCursor], 0)
+		if pid == "" {
+			m.logs = "🚧 ps args don't include a PID column\n"
+			return m, nil
+		}
+		id, name := m.top.containerID, m.top.name
+		m.openPrompt("signal for pid "+pid, "HUP", func(m model, signal string) (model, tea.Cmd) {
+			signal = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(signal)), "SIG")
+			if signal == "" {
+				return m, nil
+			}
+			if m.dryRunMode {
+				m.dryRun("signalling", []string{dockerCommand("exec", shortID(id), "kill", "-"+signal, pid)})
+				return m, nil
+			}
+			cmd := m.sendSignal(id, name, pid, signal)
+			return m, cmd
+		})
+	}
+	return m, nil
+}
+
+// buildTopView render the process table, sized to fit in the detail pane
+func buildTopView(m model) string {
+	t := m.top
+	title := fmt.Sprintf("Processes (ps %s)", t.psArgs)
+	switch {
+	case t.loading:
+		return title + "\n\nLoading..."
+	case t.err != nil:
+		return title + "\n\n🚧 " + runewidth.Truncate(t.err.Error(), fixedBodyRWidth, "...")
+	}
+
+	// header
+	var header string
+	for i, col := range topColumns {
+		name := strings.ToUpper(col.name)
+		if i == t.sortColumn {
+			arrow := "↑"
+			if t.sortDesc {
+				arrow = "↓"
+			}
+			name += arrow
+		}
+		header += padCell(name, col.width)
+	}
+
+	rows := []string{}
+	for _, p := range t.sortedProcesses() {
+		var row string
+		for i, col := range topColumns {
+			row += padCell(t.cell(p, i), col.width)
+		}
+		rows = append(rows, runewidth.Truncate(row, fixedBodyRWidth-2, "…"))
+	}
+
+	return title + "\n\n" + topHeaderStyle.Render("  "+header) + "\n" +
+		renderRows(rows, m.drillCursor, m.drillOffset, drillHeight(m))
+}
+
+// padCell truncate & pad s to width, 0 width means no padding
+func padCell(s string, width int) string {
+	if width == 0 {
+		return s
+	}
+	s = runewidth.Truncate(s, width-1, "")
+	return s + strings.Repeat(" ", width-runewidth.StringWidth(s))
+}
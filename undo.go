@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const undoPrefix = "undo "
+
+// undoStep is the job that bring a container back to its prior state
+type undoStep struct {
+	op           string
+	action       string
+	desiredState string
+}
+
+// undoSteps map an action to how it can be reverted, keyed by the state
+// container was in before the action. actions not listed are not undoable
+var undoSteps = map[string]map[string]undoStep{
+	"stopping": {
+		"running":    {op: opStart, action: "starting", desiredState: "running"},
+		"restarting": {op: opStart, action: "starting", desiredState: "running"},
+	},
+	"killing": {
+		"running": {op: opStart, action: "starting", desiredState: "running"},
+	},
+	"pausing": {
+		"running": {op: opUnpause, action: "unpausing", desiredState: "running"},
+	},
+	"unpausing": {
+		"paused": {op: opPause, action: "pausing", desiredState: "paused"},
+	},
+	"starting": {
+		"exited":  {op: opStop, action: "stopping", desiredState: "exited"},
+		"created": {op: opStop, action: "stopping", desiredState: "exited"},
+	},
+}
+
+// undoable tell whether entry can be reverted, undo itself is not
+func (e historyEntry) undoable() bool {
+	_, ok := undoSteps[e.Action]
+	return ok
+}
+
+// containerState return the current state of container with id
+func containerState(m model, id string) string {
+	for _, c := range m.containers {
+		if c.id == id {
+			return c.state
+		}
+	}
+	return ""
+}
+
+// undoLastAndWriteLog revert the newest action that hasn't been undone yet,
+// each completed target is brought back to the state it had before
+func undoLastAndWriteLog(m model) (model, string) {
+	var entry *historyEntry
+	for i := len(m.history) - 1; i >= 0; i-- {
+		e := &m.history[i]
+		if !e.Undone && !strings.HasPrefix(e.Action, undoPrefix) {
+			entry = e
+			break
+		}
+	}
+	if entry == nil {
+		return m, "🚧 Nothing to undo\n"
+	}
+	if !entry.undoable() {
+		return m, fmt.Sprintf("🚧 Last action (%s) can't be undone\n", entry.Action)
+	}
+
+	steps := undoSteps[entry.Action]
+	jobsByAction := make(map[string][]job)
+	var undone []historyTarget
+	var skipped int
+	for _, t := range entry.Targets {
+		step, ok := steps[t.PriorState]
+		if t.Outcome != outcomeCompleted || !ok || containerState(m, t.ID) == "" {
+			skipped++
+			continue
+		}
+		jobsByAction[step.action] = append(jobsByAction[step.action], job{id: t.ID, name: t.Name, op: step.op})
+		undone = append(undone, t)
+	}
+
+	var skipLog string
+	if skipped > 0 {
+		skipLog = fmt.Sprintf(
+			"🚧 Skip undoing %v container(s), already gone or not changed by the action...\n",
+			itemCountStyle.Render(fmt.Sprintf("%d", skipped)))
+	}
+
+	// only show the commands, the entry can still be undone for real
+	if m.dryRunMode {
+		commands := []string{}
+		for _, t := range undone {
+			step := steps[t.PriorState]
+			commands = append(commands, job{id: t.ID, name: t.Name, op: step.op}.command())
+		}
+		if len(commands) > 0 {
+			m.dryRun(undoPrefix+entry.Action, commands)
+		}
+		return m, skipLog
+	}
+
+	for _, t := range undone {
+		step := steps[t.PriorState]
+		addProcess(&m, t.ID, t.Name, step.action, step.desiredState)
+	}
+	entry.Undone = true
+
+	var logs string
+	for action, jobs := range jobsByAction {
+		m.submit(undoPrefix+action, jobs)
+		logs += fmt.Sprintf(
+			"↩️ Undo %s: %s %v container(s)\n",
+			entry.Action, action, itemCountStyle.Render(fmt.Sprintf("%d", len(jobs))))
+	}
+	return m, logs + skipLog
+}
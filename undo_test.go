@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func stoppedWebModel(dryRun bool) model {
+	return model{
+		containers: []Container{{id: "abc123", name: "web", state: "exited"}},
+		processes:  map[string]process{},
+		history: []historyEntry{{
+			ID:     1,
+			Action: "stopping",
+			Targets: []historyTarget{
+				{ID: "abc123", Name: "web", PriorState: "running", Outcome: outcomeCompleted},
+			},
+		}},
+		dryRunMode: dryRun,
+		executor:   newExecutor(&fakeBackend{}, 1),
+	}
+}
+
+func TestUndoDryRun(t *testing.T) {
+	m, _ := undoLastAndWriteLog(stoppedWebModel(true))
+	if len(m.processes) != 0 {
+		t.Errorf("processes = %v, want none in dry run", m.processes)
+	}
+	if m.history[0].Undone {
+		t.Error("entry marked undone in dry run")
+	}
+	if len(m.history) != 1 {
+		t.Errorf("history has %d entries, want 1", len(m.history))
+	}
+	if !strings.Contains(m.dryRunScript, "docker start abc123") {
+		t.Errorf("script = %q, want docker start", m.dryRunScript)
+	}
+}
+
+func TestUndo(t *testing.T) {
+	m, _ := undoLastAndWriteLog(stoppedWebModel(false))
+	if p, ok := m.processes["abc123"]; !ok || p.action != "starting" {
+		t.Errorf("processes = %v, want web starting", m.processes)
+	}
+	if !m.history[0].Undone {
+		t.Error("entry not marked undone")
+	}
+	if len(m.history) != 2 || m.history[1].Action != undoPrefix+"starting" {
+		t.Errorf("history = %+v, want the undo entry", m.history)
+	}
+}
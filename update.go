@@ -5,7 +5,6 @@ import (
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
-	docker "github.com/fsouza/go-dockerclient"
 )
 
 func (m model) togglePageKey() keyMap {
@@ -19,6 +18,19 @@ func (m model) togglePageKey() keyMap {
 		m.keys.Start.Unbind()
 		m.keys.Pause.Unbind()
 		m.keys.Unpause.Unbind()
+		m.keys.SelectExited.Unbind()
+		m.keys.SelectUnhealthy.Unbind()
+		m.keys.SelectImage.Unbind()
+		m.keys.SelectProject.Unbind()
+		m.keys.Diff.Unbind()
+		m.keys.Top.Unbind()
+		m.keys.Files.Unbind()
+		m.keys.Rename.Unbind()
+		m.keys.EditLimits.Unbind()
+		m.keys.Commit.Unbind()
+		m.keys.Watch.Unbind()
+		m.keys.Pin.Unbind()
+		m.keys.PinnedOnly.Unbind()
 	case pageVolume:
 		m.keys.Restart.Unbind()
 		m.keys.Kill.Unbind()
@@ -26,8 +38,100 @@ func (m model) togglePageKey() keyMap {
 		m.keys.Start.Unbind()
 		m.keys.Pause.Unbind()
 		m.keys.Unpause.Unbind()
+		m.keys.SelectExited.Unbind()
+		m.keys.SelectUnhealthy.Unbind()
+		m.keys.SelectImage.Unbind()
+		m.keys.SelectProject.Unbind()
+		m.keys.Diff.Unbind()
+		m.keys.Top.Unbind()
+		m.keys.Files.Unbind()
+		m.keys.Rename.Unbind()
+		m.keys.EditLimits.Unbind()
+		m.keys.Commit.Unbind()
+		m.keys.Watch.Unbind()
+		m.keys.Pin.Unbind()
+		m.keys.PinnedOnly.Unbind()
+	case pageLog:
+		m.keys.Remove.Unbind()
+		m.keys.Clean.Unbind()
+		m.keys.Restart.Unbind()
+		m.keys.Kill.Unbind()
+		m.keys.Stop.Unbind()
+		m.keys.Start.Unbind()
+		m.keys.Pause.Unbind()
+		m.keys.Unpause.Unbind()
+		m.keys.Toggle.Unbind()
+		m.keys.SelectAll.Unbind()
+		m.keys.Visual.Unbind()
+		m.keys.Invert.Unbind()
+		m.keys.SelectExited.Unbind()
+		m.keys.SelectUnhealthy.Unbind()
+		m.keys.SelectImage.Unbind()
+		m.keys.SelectProject.Unbind()
+		m.keys.Sort.Unbind()
+		m.keys.SortOrder.Unbind()
+		m.keys.Inspect.Unbind()
+		m.keys.Graph.Unbind()
+		m.keys.Diff.Unbind()
+		m.keys.Top.Unbind()
+		m.keys.Files.Unbind()
+		m.keys.Rename.Unbind()
+		m.keys.EditLimits.Unbind()
+		m.keys.Commit.Unbind()
+		m.keys.Watch.Unbind()
+		m.keys.Pin.Unbind()
+		m.keys.PinnedOnly.Unbind()
+	case pageSystem:
+		m.keys.Remove.Unbind()
+		m.keys.Restart.Unbind()
+		m.keys.Kill.Unbind()
+		m.keys.Stop.Unbind()
+		m.keys.Start.Unbind()
+		m.keys.Pause.Unbind()
+		m.keys.Unpause.Unbind()
+		m.keys.Visual.Unbind()
+		m.keys.SelectExited.Unbind()
+		m.keys.SelectUnhealthy.Unbind()
+		m.keys.SelectImage.Unbind()
+		m.keys.SelectProject.Unbind()
+		m.keys.Sort.Unbind()
+		m.keys.SortOrder.Unbind()
+		m.keys.Inspect.Unbind()
+		m.keys.Graph.Unbind()
+		m.keys.Diff.Unbind()
+		m.keys.Top.Unbind()
+		m.keys.Files.Unbind()
+		m.keys.Rename.Unbind()
+		m.keys.EditLimits.Unbind()
+		m.keys.Commit.Unbind()
+		m.keys.Watch.Unbind()
+		m.keys.Pin.Unbind()
+		m.keys.PinnedOnly.Unbind()
 	case pageContainer:
 	}
+	if m.page != pageContainer || engine != enginePodman {
+		m.keys.Pods.Unbind()
+	}
+	m.keys.Custom = customBindings(m.config.Actions, m.page)
+
+	if readOnly {
+		m.keys.Remove.Unbind()
+		m.keys.Clean.Unbind()
+		m.keys.Kill.Unbind()
+		m.keys.Stop.Unbind()
+		m.keys.Start.Unbind()
+		m.keys.Pause.Unbind()
+		m.keys.Unpause.Unbind()
+		m.keys.Restart.Unbind()
+		m.keys.Undo.Unbind()
+		m.keys.Rename.Unbind()
+		m.keys.EditLimits.Unbind()
+		m.keys.Commit.Unbind()
+		m.keys.Upload.Unbind()
+		m.keys.Signal.Unbind()
+		m.keys.Confirm.Unbind() // prune
+		m.keys.Custom = nil     // may run anything
+	}
 	return m.keys
 }
 
@@ -40,6 +144,10 @@ func getCurrentViewItemCount(m model) int {
 		itemCount = len(m.images)
 	case pageVolume:
 		itemCount = len(m.volumes)
+	case pageLog:
+		itemCount = len(m.history)
+	case pageSystem:
+		itemCount = len(dfCategoryNames)
 	}
 	return itemCount
 }
@@ -49,18 +157,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case TickMsg:
+		cursorID, selectedIDs := m.itemID(m.cursor), m.selectedIDs()
+
 		// containers
-		containers := getContainers()
-		m.containers = containers
+		containers := m.sizes.apply(getContainers())
+		m.containers = m.visibleContainers(containers)
 
 		// images
-		images := getImages()
+		images := getImages(containers)
 		m.images = images
 
 		// volumes
 		volumes := getVolumes()
 		m.volumes = volumes
 
+		// sort
+		m.applySort()
+		m.restoreSelection(cursorID, selectedIDs)
+
 		// cursor
 		if m.cursor == -1 {
 			m.cursor = 0
@@ -74,9 +188,190 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		// processes
-		m.processes = updatePendingProcesses(m)
+		m.updatePendingProcesses()
+
+		cmds := []tea.Cmd{doTick()}
+
+		// container sizes, only while sorted by them
+		if m.sizeSorted() && m.sizes.stale() {
+			m.sizes.loading = true
+			cmds = append(cmds, fetchContainerSizes)
+		}
+
+		// disk usage, (re)loaded when entering system page
+		if m.page == pageSystem && m.system.usage == nil && m.system.err == nil && !m.system.loading {
+			m.system.loading = true
+			cmds = append(cmds, fetchDiskUsage)
+		}
+
+		return m, tea.Batch(cmds...)
+
+	case ContainerSizesMsg:
+		if err := m.sizes.set(msg); err != nil {
+			m.logs = "🚧 Failed to get container sizes: " + err.Error() + "\n"
+			return m, nil
+		}
+		cursorID, selectedIDs := m.itemID(m.cursor), m.selectedIDs()
+		m.containers = m.sizes.apply(m.containers)
+		m.applySort()
+		m.restoreSelection(cursorID, selectedIDs)
+		return m, nil
+
+	case JobDoneMsg:
+		finishProcess(&m, msg.id, msg.err)
+		if msg.done == msg.total {
+			delete(m.progress, msg.batch)
+			if msg.label == "pruning" {
+				cmd := m.finishPrune()
+				return m, tea.Batch(m.executor.listen(), cmd)
+			}
+		} else {
+			m.progress[msg.batch] = msg
+		}
+		return m, m.executor.listen()
+
+	case DiffMsg:
+		if msg.containerID == m.diff.containerID {
+			m.diff.loading = false
+			m.diff.changes = msg.changes
+			m.diff.err = msg.err
+		}
+		return m, nil
+
+	case TopMsg:
+		if m.drill != drillTop || msg.containerID != m.top.containerID {
+			return m, nil // closed, stop polling
+		}
+		m.top.loading = false
+		m.top.titles = msg.result.Titles
+		m.top.processes = msg.result.Processes
+		m.top.err = msg.err
+		m.moveDrillCursor(0, len(m.top.processes))
+		return m, doTopTick(m.top.containerID, m.top.psArgs)
+
+	case SignalMsg:
+		m.recordResult(msg.entry, msg.err)
+		if msg.err != nil {
+			m.logs = fmt.Sprintf("❌ Failed sending %s to pid %s: %v %s\n", msg.signal, msg.pid, msg.err, msg.output)
+		} else {
+			m.logs = fmt.Sprintf("📨 Sent %s to pid %s\n", msg.signal, msg.pid)
+		}
+		return m, nil
+
+	case FilesMsg:
+		if m.drill == drillFiles && msg.containerID == m.files.containerID && msg.dir == m.files.dir {
+			m.files.loading = false
+			m.files.entries = msg.entries
+			m.files.truncated = msg.truncated
+			m.files.err = msg.err
+		}
+		return m, nil
+
+	case FilePreviewMsg:
+		if p := m.files.preview; p != nil && msg.containerID == m.files.containerID && msg.path == p.path {
+			p.loading = false
+			p.lines = previewLines(msg.content)
+			p.err = msg.err
+		}
+		return m, nil
+
+	case TransferMsg:
+		if msg.upload {
+			m.recordResult(msg.entry, msg.err)
+		}
+		m.logs = transferLog(msg)
+		// show the uploaded files
+		if msg.upload && msg.err == nil && m.drill == drillFiles && m.files.preview == nil &&
+			msg.containerID == m.files.containerID && msg.dst == m.files.dir {
+			cmd := m.changeDir(m.files.dir)
+			return m, cmd
+		}
+		return m, nil
+
+	case InspectMsg:
+		if v := m.inspect; m.drill == drillInspect && msg.kind == v.kind && msg.id == v.id && v.loading {
+			m.inspect.loading = false
+			m.inspect.root = msg.root
+			m.inspect.err = msg.err
+			if msg.root != nil {
+				for _, n := range msg.root.children {
+					collapseNested(n, m.inspect.collapsed)
+				}
+			}
+		}
+		return m, nil
+
+	case GraphMsg:
+		if g := m.graph; m.drill == drillGraph && msg.kind == g.kind && msg.id == g.id {
+			m.graph.loading = false
+			m.graph.root = msg.root
+			m.graph.err = msg.err
+		}
+		return m, nil
+
+	case HostConfigMsg:
+		return openUpdateForm(m, msg)
+
+	case OutputMsg:
+		return handleOutput(m, msg)
+
+	case DockerEventMsg:
+		return handleDockerEvent(m, msg)
+
+	case NotifyMsg:
+		m.notify(msg)
+		return m, nil
+
+	case CommitMsg:
+		m.recordResult(msg.entry, msg.err)
+		m.logs += commitLog(msg)
+		if msg.err == nil {
+			m.newImage = msg.imageID
+		}
+		return m, nil
+
+	case ContainerEditMsg:
+		m.recordResult(msg.entry, msg.err)
+		m.logs += editLog(msg)
+		return m, nil
+
+	case DiskUsageMsg:
+		m.system.loading = false
+		m.system.err = msg.err
+		if msg.usage != nil {
+			m.system.usage = msg.usage
+		}
+		if m.system.pruneBefore > 0 && msg.usage != nil {
+			reclaimed := m.system.pruneBefore - msg.usage.totalSize()
+			if reclaimed < 0 {
+				reclaimed = 0
+			}
+			m.logs += fmt.Sprintf("✅ Prune reclaimed %s\n", itemCountStyle.Render(convertSizeToHumanRedable(reclaimed)))
+			m.system.pruneBefore = 0
+		}
+		return m, nil
+
+	case BuildPruneMsg:
+		m.recordResult(msg.entry, msg.err)
+		if msg.err != nil {
+			m.logs += fmt.Sprintf("❌ Failed pruning build cache: %v\n", msg.err)
+		} else {
+			m.logs += fmt.Sprintf("✅ Done pruning build cache (%s)\n", convertSizeToHumanRedable(msg.reclaimed))
+		}
+		cmd := m.finishPrune()
+		return m, cmd
+
+	case StatsMsg:
+		m.stats = msg.stats
+		if field := m.sorts[pageContainer].field; field == sortByCPU || field == sortByMemory {
+			cursorID, selectedIDs := m.itemID(m.cursor), m.selectedIDs()
+			sortContainers(m.containers, m.sorts[pageContainer], m.stats, m.config.pinSet())
+			m.restoreSelection(cursorID, selectedIDs)
+		}
+		return m, doStatsTick()
 
-		return m, doTick()
+	case tea.MouseMsg:
+		return handleMouse(m, msg)
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -84,6 +379,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.dryRunLog != "" && !key.Matches(msg, m.keys.Copy) {
+			m.dryRunLog = ""
+		}
+		if m.prompt != nil {
+			return handlePromptKeys(m, msg)
+		}
+		if m.form != nil {
+			return handleFormKeys(m, msg)
+		}
+		if m.palette != nil {
+			return handlePaletteKeys(m, msg)
+		}
+		if m.drill != drillNone {
+			return handleDrillKeys(m, msg)
+		}
 		switch m.page {
 		case pageContainer:
 			if getCurrentViewItemCount(m) > 0 {
@@ -100,6 +410,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return handleVolumeKeys(m, msg)
 			}
 			return handleCommonKeys(&m, msg)
+		case pageLog:
+			return handleCommonKeys(&m, msg)
+		case pageSystem:
+			return handleSystemKeys(m, msg)
 		}
 
 		handleCommonKeys(&m, msg)
@@ -108,23 +422,23 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// findDangling return a list of Image has name "<none>"
+// findDangling return a list of Image without tag
 func findDangling(images []Image) []Image {
 	danglingImages := []Image{}
 	for _, img := range images {
-		if img.name == "<none>" {
+		if img.dangling() {
 			danglingImages = append(danglingImages, img)
 		}
 	}
 	return danglingImages
 }
 
-// getContainers return a list of Container that are created using
-// this image
+// findAssociatedContainersInUse return the running or paused containers
+// created from this image, by tag, id or digest
 func (img Image) findAssociatedContainersInUse(m model) []Container {
 	containers := []Container{}
 	for _, c := range m.containers {
-		if c.ancestor == img.name && (c.state == "running" || c.state == "paused") {
+		if img.isAncestorOf(c) && (c.state == "running" || c.state == "paused") {
 			containers = append(containers, c)
 		}
 	}
@@ -132,8 +446,6 @@ func (img Image) findAssociatedContainersInUse(m model) []Container {
 }
 
 func handleVolumeKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
 	// handle 0 volumes
 	if getCurrentViewItemCount(m) == 0 {
 		return handleCommonKeys(&m, msg)
@@ -141,8 +453,29 @@ func handleVolumeKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.keys.Remove): // remove
-		// TODO: remove
-		return m, cmd
+		targets := []Volume{}
+		if len(m.selected) == 0 {
+			targets = append(targets, m.volumes[m.cursor])
+		} else {
+			for k := range m.selected {
+				targets = append(targets, m.volumes[k])
+			}
+		}
+
+		jobs := []job{}
+		for _, v := range targets {
+			jobs = append(jobs, job{id: v.name, name: v.name, op: opRemoveVolume})
+			desiredState := "x"
+			addProcess(&m, v.name, v.name, "removing", desiredState)
+		}
+
+		m.submit("removing", jobs)
+		m.logs = fmt.Sprintf(
+			"🗑️ Removing %v volume(s), this can't be undone\n",
+			itemCountStyle.Render(fmt.Sprintf("%d", len(jobs))))
+		m.clearSelection()
+		m.cursor = -1
+		return m, nil
 
 	default:
 		return handleCommonKeys(&m, msg)
@@ -150,8 +483,6 @@ func handleVolumeKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	var cmd tea.Cmd
-
 	// handle 0 images
 	if getCurrentViewItemCount(m) == 0 {
 		return handleCommonKeys(&m, msg)
@@ -159,19 +490,15 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch {
 	case key.Matches(msg, m.keys.Clean): // clean
-		client, err := docker.NewClientFromEnv()
-		if err != nil {
-			m.logs += "Failed to create Docker client"
-		}
-
 		res := actionResultImages{}
 
 		// filter dangling images
 		danglingImages := findDangling(m.images)
+		jobs := []job{}
 		for _, img := range danglingImages {
-			go removeImage(client, img.id)
+			jobs = append(jobs, job{id: img.id, name: img.name, op: opRemoveImage})
 			desiredState := "x"
-			addProcess(&m, img.id, desiredState)
+			addProcess(&m, img.id, img.name, "removing", desiredState)
 			res.success = append(res.success, img)
 		}
 
@@ -180,7 +507,7 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		if successCount > 0 {
 			logs += fmt.Sprintf(
-				"🗑️ Remove %v image(s)\n",
+				"🗑️ Remove %v image(s), this can't be undone\n",
 				itemCountStyle.Render(fmt.Sprintf("%d", successCount)))
 		}
 
@@ -190,16 +517,12 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 		}
 
+		m.submit("removing", jobs)
 		m.logs = logs
 		m.cursor = -1
-		return m, cmd
+		return m, nil
 
 	case key.Matches(msg, m.keys.Remove): // remove
-		client, err := docker.NewClientFromEnv()
-		if err != nil {
-			m.logs += "Failed to create Docker client"
-		}
-
 		targets := []Image{}
 		if len(m.selected) == 0 {
 			targets = append(targets, m.images[m.cursor])
@@ -210,6 +533,7 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 		res := actionResultImages{}
+		jobs := []job{}
 		// for now show 1 dependent erorr at a time
 		for _, img := range targets {
 			containersInUse := img.findAssociatedContainersInUse(m)
@@ -217,9 +541,9 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				res.failed = append(res.failed, img)
 				res.associatedContainers = containersInUse
 			} else {
-				go removeImage(client, img.id)
+				jobs = append(jobs, job{id: img.id, name: img.name, op: opRemoveImage})
 				desiredState := "x"
-				addProcess(&m, img.id, desiredState)
+				addProcess(&m, img.id, img.name, "removing", desiredState)
 				res.success = append(res.success, img)
 			}
 		}
@@ -229,7 +553,7 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		if successCount > 0 {
 			logs += fmt.Sprintf(
-				"🗑️ Remove %v image(s)\n",
+				"🗑️ Remove %v image(s), this can't be undone\n",
 				itemCountStyle.Render(fmt.Sprintf("%d", successCount)))
 		}
 
@@ -239,10 +563,11 @@ func handleImageKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				itemCountStyle.Render(fmt.Sprintf("%d", failedCount)))
 		}
 
+		m.submit("removing", jobs)
 		m.logs = logs
-		m.selected = make(map[int]struct{})
+		m.clearSelection()
 		m.cursor = -1
-		return m, cmd
+		return m, nil
 
 	default:
 		return handleCommonKeys(&m, msg)
@@ -277,6 +602,73 @@ func handleContainerKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case key.Matches(msg, m.keys.Unpause): // unpause
 		return unpauseAndWriteLog(m)
+
+	case key.Matches(msg, m.keys.Diff): // filesystem diff
+		return openDiff(m)
+
+	case key.Matches(msg, m.keys.Top): // process list
+		return openTop(m)
+
+	case key.Matches(msg, m.keys.Files): // file browser
+		return openFiles(m)
+
+	case key.Matches(msg, m.keys.Rename): // rename
+		return openRename(m)
+
+	case key.Matches(msg, m.keys.EditLimits): // restart policy & resources
+		return openUpdate(m)
+
+	case key.Matches(msg, m.keys.Commit): // commit to image
+		return openCommit(m)
+
+	case key.Matches(msg, m.keys.Watch): // notify state changes
+		return toggleWatch(m)
+
+	case key.Matches(msg, m.keys.Pin): // pin to top
+		return togglePin(m)
+
+	case key.Matches(msg, m.keys.SelectExited): // select exited
+		count := m.selectContainersWhere(func(c Container) bool {
+			return c.state == "exited"
+		})
+		m.logs = fmt.Sprintf(
+			"🎯 Selected %v exited container(s)\n",
+			itemCountStyle.Render(fmt.Sprintf("%d", count)))
+		return m, nil
+
+	case key.Matches(msg, m.keys.SelectUnhealthy): // select unhealthy
+		count := m.selectContainersWhere(func(c Container) bool {
+			return c.health == healthUnhealthy
+		})
+		m.logs = fmt.Sprintf(
+			"🎯 Selected %v unhealthy container(s)\n",
+			itemCountStyle.Render(fmt.Sprintf("%d", count)))
+		return m, nil
+
+	case key.Matches(msg, m.keys.SelectImage): // select same image as cursor
+		image := m.containers[m.cursor].ancestor
+		count := m.selectContainersWhere(func(c Container) bool {
+			return c.ancestor == image
+		})
+		m.logs = fmt.Sprintf(
+			"🎯 Selected %v container(s) from image %s\n",
+			itemCountStyle.Render(fmt.Sprintf("%d", count)), image)
+		return m, nil
+
+	case key.Matches(msg, m.keys.SelectProject): // select same compose project as cursor
+		project, ok := m.containers[m.cursor].labels[composeProjectLabel]
+		if !ok {
+			m.logs = "🚧 Container is not part of a compose project\n"
+			return m, nil
+		}
+		count := m.selectContainersWhere(func(c Container) bool {
+			return c.labels[composeProjectLabel] == project
+		})
+		m.logs = fmt.Sprintf(
+			"🎯 Selected %v container(s) in compose project %s\n",
+			itemCountStyle.Render(fmt.Sprintf("%d", count)), project)
+		return m, nil
+
 	default:
 		return handleCommonKeys(&m, msg)
 	}
@@ -285,9 +677,21 @@ func handleContainerKeys(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func handleCommonKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch {
+	case key.Matches(msg, m.keys.PinnedOnly): // container page only, may be empty
+		return togglePinnedOnly(*m)
+
+	case key.Matches(msg, m.keys.Palette): // command palette
+		return openPalette(*m)
+
+	case key.Matches(msg, m.keys.DryRun): // only show docker commands
+		return toggleDryRun(m)
+
+	case key.Matches(msg, m.keys.Copy) && m.dryRunScript != "": // copy dry run
+		return copyDryRunScript(m)
+
 	case key.Matches(msg, m.keys.SelectAll): // select all
 		// select/clear based on current page
-		var items []any // container|image
+		var items []any // container|image|volume|prune category
 		switch m.page {
 		case pageContainer:
 			items = make([]any, len(m.containers))
@@ -304,10 +708,15 @@ func handleCommonKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			for i, volume := range m.volumes {
 				items[i] = volume
 			}
+		case pageSystem:
+			items = make([]any, len(dfCategoryNames))
+			for i, name := range dfCategoryNames {
+				items[i] = name
+			}
 		}
 
 		if len(items) == len(m.selected) {
-			m.selected = make(map[int]struct{})
+			m.clearSelection()
 		} else {
 			for i := range items {
 				m.selected[i] = struct{}{}
@@ -315,11 +724,55 @@ func handleCommonKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case key.Matches(msg, m.keys.Visual): // visual mode
+		m.toggleVisual()
+		return *m, nil
+
+	case key.Matches(msg, m.keys.Invert): // invert selection
+		m.invertSelection()
+		return *m, nil
+
 	case key.Matches(msg, m.keys.Clear): // clear selection
 		m.logs = ""
-		m.selected = make(map[int]struct{})
+		m.clearSelection()
 		return m, nil
 
+	case key.Matches(msg, m.keys.Sort): // toggle direction, then cycle sort field
+		fields := availableSortFields(m.page, len(m.stats) > 0)
+		if len(fields) > 0 {
+			m.setSort(nextSort(fields, m.sorts[m.page]))
+		}
+		return *m, nil
+
+	case key.Matches(msg, m.keys.SortOrder): // toggle ascending/descending
+		s := m.sorts[m.page]
+		s.desc = !s.desc
+		m.setSort(s)
+		return *m, nil
+
+	case key.Matches(msg, m.keys.Undo): // undo last action
+		var logs string
+		*m, logs = undoLastAndWriteLog(*m)
+		m.logs = logs
+		return *m, nil
+
+	case key.Matches(msg, m.keys.Inspect): // full inspect
+		return openInspect(*m)
+
+	case key.Matches(msg, m.keys.Graph): // relations graph
+		return openGraph(*m)
+
+	case key.Matches(msg, m.keys.Pods): // containers grouped by pod
+		cmd := m.startGraph(graphPods, "", "")
+		return *m, cmd
+
+	case key.Matches(msg, m.keys.Cancel): // cancel queued actions
+		if len(m.progress) > 0 {
+			m.executor.cancelQueued()
+			m.logs = "🚫 Cancelling queued action(s)\n"
+		}
+		return *m, nil
+
 	case key.Matches(msg, m.keys.Quit): // quit
 		return m, tea.Quit
 
@@ -331,6 +784,8 @@ func handleCommonKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.cursor = itemCount - 1
 		}
+		m.descOffset = 0
+		m.extendVisual()
 
 	case key.Matches(msg, m.keys.Down): // move cursor down
 		itemCount := getCurrentViewItemCount(*m)
@@ -341,6 +796,8 @@ func handleCommonKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.cursor = 0
 		}
+		m.descOffset = 0
+		m.extendVisual()
 
 	case key.Matches(msg, m.keys.Toggle): // toggle selection
 		_, ok := m.selected[m.cursor]
@@ -364,12 +821,23 @@ func handleCommonKeys(m *model, msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Page3): // page 3: volumes
 		m.setPage(pageVolume)
 
+	case key.Matches(msg, m.keys.Page4): // page 4: history
+		m.setPage(pageLog)
+
+	case key.Matches(msg, m.keys.Page5): // page 5: system
+		m.setPage(pageSystem)
+
 	case key.Matches(msg, m.keys.Tab): // switch tab
 		if m.page == pageContainer {
 			m.setPage(pageImage)
 		} else {
 			m.setPage(pageContainer)
 		}
+
+	default: // built-in keys win over custom actions
+		if a, ok := m.customActionOf(msg); ok {
+			return runCustomAction(*m, a)
+		}
 	}
 	return *m, nil
 }
@@ -379,7 +847,15 @@ func (m *model) setPage(targetPage int) {
 		m.page = targetPage
 		m.logs = ""
 		m.cursor = 0
-		m.selected = make(map[int]struct{})
+		m.descOffset = 0
+		m.clearSelection()
 		m.keys = m.togglePageKey()
+		if targetPage == pageImage {
+			m.cursorToCommittedImage()
+		}
+		if targetPage == pageSystem {
+			m.system.usage = nil // reload on next tick
+			m.system.err = nil
+		}
 	}
 }
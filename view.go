@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/docker/go-units"
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/mattn/go-runewidth"
 	"github.com/muesli/reflow/wrap"
@@ -29,26 +30,35 @@ func padBodyHeight(s *string, itemCount int) {
 	}
 }
 
-func padOuterComponent(s *string, windowWidth int) {
-	var outerPad, innerPad, longest int
+// outerComponentPad return the left padding that centers a component of
+// sWidth in the window
+func outerComponentPad(sWidth, windowWidth int) int {
+	if windowWidth <= 0 {
+		return 0
+	}
+	outerPad := (windowWidth - fullWidth) / 2
+	innerPad := (fullWidth - sWidth) / 2
+	return outerPad + innerPad
+}
 
-	// get width of longer help string (fullHelp)
-	split := strings.Split(*s, "\n")
-	for _, line := range split {
+func longestLineWidth(s string) int {
+	var longest int
+	for _, line := range strings.Split(s, "\n") {
 		if lipgloss.Width(line) > longest {
 			longest = lipgloss.Width(line)
 		}
 	}
-	sWidth := longest
+	return longest
+}
 
-	if windowWidth > 0 {
-		outerPad = (windowWidth - fullWidth) / 2
-		innerPad = (fullWidth - sWidth) / 2
-	}
+func padOuterComponent(s *string, windowWidth int) {
+	// get width of longer help string (fullHelp)
+	split := strings.Split(*s, "\n")
+	pad := outerComponentPad(longestLineWidth(*s), windowWidth)
 
 	var newS string
 	for _, line := range split {
-		newS += strings.Repeat(" ", outerPad+innerPad) + line + "\n"
+		newS += strings.Repeat(" ", pad) + line + "\n"
 	}
 	*s = newS
 }
@@ -62,18 +72,117 @@ func padItemName(name string, maxLen int) string {
 	return name
 }
 
-func buildTitleView(m model) string {
+// scrollLines drop the first offset lines of s, always keep the last line
+func scrollLines(s string, offset int) string {
+	split := strings.Split(s, "\n")
+	if offset >= len(split) {
+		offset = len(split) - 1
+	}
+	if offset <= 0 {
+		return s
+	}
+	return strings.Join(split[offset:], "\n")
+}
+
+// titleTab is a clickable page tab, start & end are relative to the
+// beginning of the title text
+type titleTab struct {
+	page  int
+	start int
+	end   int
+}
+
+func buildTitleText(m model) (string, []titleTab) {
 	s := "🐳 Killer Whale" + "  "
+	if readOnly {
+		s += readOnlyStyle.Render("READ-ONLY") + "  "
+	}
+	if engine == enginePodman {
+		s += sortStyle.Render("podman") + "  "
+	}
+	if host := remoteHost(); host != "" {
+		s += sortStyle.Render("⇄ "+host) + "  "
+	}
+
+	// page tabs
+	tabs := []titleTab{}
+	for _, page := range []int{pageContainer, pageImage, pageVolume, pageLog, pageSystem} {
+		style := tabStyle
+		if m.page == page {
+			style = activeTabStyle
+		}
+		start := lipgloss.Width(s)
+		s += style.Render(fmt.Sprintf("%d %s", page+1, pageNames[page]))
+		tabs = append(tabs, titleTab{page: page, start: start, end: lipgloss.Width(s)})
+		s += " "
+	}
+	s += " "
+
+	if sort, ok := m.sorts[m.page]; ok {
+		s += sortStyle.Render("sort: "+sort.String()) + "  "
+	}
+
+	var unhealthy int
+	for _, c := range m.containers {
+		if c.health == healthUnhealthy {
+			unhealthy++
+		}
+	}
+	if unhealthy > 0 {
+		s += unhealthyStyle.Render(fmt.Sprintf("⚠ %d unhealthy", unhealthy)) + "  "
+	}
+	return s, tabs
+}
+
+func buildTitleView(m model) string {
+	s, _ := buildTitleText(m)
 	padOuterComponent(&s, m.width)
 	s = strings.TrimSuffix(s, "\n")
 	return s
 }
 
+// ----------------------------- status view -----------------------------
+
+func buildStatusView(m model) string {
+	var s string
+
+	// progress of running batches, oldest first
+	batches := []int{}
+	for batch := range m.progress {
+		batches = append(batches, batch)
+	}
+	sort.Ints(batches)
+	for _, batch := range batches {
+		p := m.progress[batch]
+		s += fmt.Sprintf("⏳ %s %d/%d  ", p.label, p.done, p.total)
+	}
+
+	if m.visual {
+		s += visualStyle.Render("-- VISUAL --") + "  "
+	}
+	if m.dryRunMode {
+		s += dryRunStyle.Render("🧪 DRY RUN") + "  "
+	}
+	if m.pinnedOnly && m.page == pageContainer {
+		s += pinStyle.Render("⚑ pinned only") + "  "
+	}
+	if len(m.selected) > 0 {
+		s += fmt.Sprintf("%v selected", itemCountStyle.Render(fmt.Sprintf("%d", len(m.selected))))
+	}
+	if s == "" {
+		return ""
+	}
+	return statusStyle.Render(s)
+}
+
 // ----------------------------- log view -----------------------------
 
 func buildLogView(m model) string {
 	var s string
 	s += m.logs
+	if m.dryRunLog != "" {
+		s = m.dryRunLog
+	}
 	logStyle.MarginLeft((fullWidth - lipgloss.Width(s)) / 2)
 	logStyle.AlignHorizontal(lipgloss.Center)
 	return logStyle.Render(s)
@@ -134,7 +243,7 @@ func buildVolumeDescShort(volume Volume) string {
 
 	if len(containers) > 0 {
 		inUse = true
-		containerName = containers[0].Names[0][1:]
+		containerName = apiContainerName(containers[0])
 	}
 
 	var desc string
@@ -157,7 +266,7 @@ func buildVolumeView(m model) (string, string) {
 
 		if m.cursor == i {
 			cursor = "❯"
-			bodyR = buildVolumeDescShort(choice)
+			bodyR = buildCursorDesc(m)
 		}
 
 		name := runewidth.Truncate(choice.name, maxVolumeNameWidth, "")
@@ -176,7 +285,7 @@ func buildVolumeView(m model) (string, string) {
 		bodyL += row + "\n"
 	}
 
-	return bodyLStyle.Render(bodyL), bodyRStyle.Render(bodyR)
+	return bodyLStyle.Render(bodyL), bodyRStyle.Render(scrollLines(bodyR, m.descOffset))
 }
 
 // ----------------------------- image view -----------------------------
@@ -204,8 +313,49 @@ func formatImageVolumes(volumeMap map[string]struct{}) string {
 	return s
 }
 
-func buildImageDescShort(id string) string {
-	client, err := docker.NewClientFromEnv()
+// formatAge is a compact age e.g. 5h, 3d, 12w, 2y, fit a 3 wide column
+func formatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw", int(d.Hours()/24/7))
+	}
+	return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+}
+
+func formatImageUsage(img Image) string {
+	switch {
+	case img.dangling():
+		return "dangling"
+	case img.unused():
+		return "unused"
+	}
+	return fmt.Sprintf("%d container(s)", img.containers)
+}
+
+func formatImageRefs(refs []string) string {
+	if len(refs) == 0 {
+		return "<none>"
+	}
+	s := ""
+	for i, ref := range refs {
+		if i > 0 {
+			s += strings.Repeat(" ", 10)
+		}
+		s += runewidth.Truncate(ref, fixedBodyRWidth-10, "...") + "\n"
+	}
+	return strings.TrimSuffix(s, "\n")
+}
+
+func buildImageDescShort(img Image) string {
+	id := img.id
+	client, err := newClient()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -215,7 +365,10 @@ func buildImageDescShort(id string) string {
 	}
 	desc := fmt.Sprintf("ID      : %v\n", runewidth.Truncate(image.ID, fixedBodyRWidth-8, "..."))
 	desc += fmt.Sprintf("Created : %s\n", image.Created.Format("2006-01-02 15:04:05"))
-	desc += fmt.Sprintf("Size    : %s\n", convertSizeToHumanRedable(image.Size))
+	desc += fmt.Sprintf("Size    : %s (virtual %s)\n", convertSizeToHumanRedable(img.size), convertSizeToHumanRedable(img.virtualSize))
+	desc += fmt.Sprintf("Used by : %s\n", formatImageUsage(img))
+	desc += fmt.Sprintf("Tags    : %s\n", formatImageRefs(img.tags))
+	desc += fmt.Sprintf("Digests : %s\n", formatImageRefs(img.digests))
 	desc += fmt.Sprintf("Cmd     : %v\n", formatCmd(image.Config.Cmd))
 	desc += fmt.Sprintf("Volumes : %v\n", formatImageVolumes(image.Config.Volumes))
 	return desc
@@ -228,18 +381,31 @@ func buildImageView(m model) (string, string) {
 		check := " "
 		if m.cursor == i {
 			cursor = "❯"
-			bodyR = buildImageDescShort(choice.id)
+			bodyR = buildCursorDesc(m)
 		}
-		name := choice.name
 		if _, ok := m.selected[i]; ok {
 			check = checkStyle.Render("✔")
 		}
+
+		// name, size, age & number of containers using it
+		name := runewidth.Truncate(choice.name, imageNameColumnWidth, "…")
+		name += strings.Repeat(" ", imageNameColumnWidth-runewidth.StringWidth(name))
+		size := units.HumanSizeWithPrecision(float64(choice.size), 3) // e.g. 187MB, 1.23GB
+		name = fmt.Sprintf("%s %6s %3s %2d", name, size, formatAge(choice.created), choice.containers)
+		switch {
+		case choice.id == m.newImage:
+			name = committedImageStyle.Render(name)
+		case choice.dangling():
+			name = danglingImageStyle.Render(name)
+		case choice.unused():
+			name = unusedImageStyle.Render(name)
+		}
 		name = padItemName(name, maxImageNameWidth)
 		row := fmt.Sprintf("%s %s %s", cursor, check, name)
 		bodyL += row
 	}
 	padBodyHeight(&bodyL, len(m.images)+2)
-	return bodyLStyle.Render(bodyL), bodyRStyle.Render(bodyR)
+	return bodyLStyle.Render(bodyL), bodyRStyle.Render(scrollLines(bodyR, m.descOffset))
 }
 
 // ----------------------------- container view -----------------------------
@@ -302,7 +468,7 @@ func formatPortsMapping(portsMap map[docker.Port][]docker.PortBinding) string {
 	return s
 }
 func buildContainerDescShort(id string) string {
-	client, err := docker.NewClientFromEnv()
+	client, err := newClient()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -316,22 +482,38 @@ func buildContainerDescShort(id string) string {
 		"ID      : %v\n",
 		runewidth.Truncate(container.ID, fixedBodyRWidth-8, "..."),
 	)
-	desc += fmt.Sprintf("Image   : %s\n", container.Config.Image)
-	desc += fmt.Sprintf("Cmd     : %s\n", strings.Join(container.Config.Cmd, " "))
+	// podman may leave out config & network settings
+	if container.Config != nil {
+		desc += fmt.Sprintf("Image   : %s\n", container.Config.Image)
+		desc += fmt.Sprintf("Cmd     : %s\n", strings.Join(container.Config.Cmd, " "))
+	}
 	desc += fmt.Sprintf("State   : %s\n", container.State.String())
-	desc += fmt.Sprintf("IP      : %s\n", container.NetworkSettings.IPAddress)
-	desc += fmt.Sprintf("Ports   : %v\n", formatPortsMapping(container.NetworkSettings.Ports))
+	if !container.State.Running {
+		desc += fmt.Sprintf("Exit    : %d\n", container.State.ExitCode)
+	}
+	desc += fmt.Sprintf("Restarts: %d\n", container.RestartCount)
+	if container.NetworkSettings != nil {
+		desc += fmt.Sprintf("IP      : %s\n", container.NetworkSettings.IPAddress)
+		desc += fmt.Sprintf("Ports   : %v\n", formatPortsMapping(container.NetworkSettings.Ports))
+	}
+	if health := container.State.Health; health.Status != "" {
+		desc += fmt.Sprintf("Health  : %s\n", formatHealth(health))
+		if len(health.Log) > 0 {
+			desc += fmt.Sprintf("Probes  :\n%s\n", formatHealthLog(health.Log))
+		}
+	}
 	return desc
 }
 
 func buildContainerView(m model) (string, string) {
 	var bodyL, bodyR string
+	pins := m.config.pinSet()
 	for i, choice := range m.containers {
 		cursor := " " // default cursor
 		check := " "
 		if m.cursor == i {
 			cursor = "❯"
-			bodyR = buildContainerDescShort(choice.id)
+			bodyR = buildCursorDesc(m)
 		}
 
 		isProcessing := checkProcess(choice.id, m.processes)
@@ -339,10 +521,27 @@ func buildContainerView(m model) (string, string) {
 		if isProcessing && m.blinkSwitch == on {
 			stateStyle = stateStyle.Copy().Foreground(pitchBlack)
 		}
+		if healthStyle, ok := healthStyleMap[choice.health]; ok && !(isProcessing && m.blinkSwitch == on) {
+			stateStyle = healthStyle
+		}
 		state := stateStyle.Render("●")
 		// state := stateStyle.Render("❖")
+
+		// show last exit code of exited container next to its name
+		var exitCode string
+		if code, ok := parseExitCode(choice.status); ok && choice.state == "exited" {
+			exitCode = exitCodeStyle.Render(fmt.Sprintf(" %d", code))
+		}
+		if m.isWatched(choice.name, choice.labels) {
+			exitCode += watchStyle.Render(" ◎")
+		}
+		var pin string
+		if pins[choice.name] {
+			pin = pinStyle.Render("⚑ ")
+		}
 		name := choice.name
-		name = runewidth.Truncate(name, maxContainerNameWidth, "...")
+		name = pin + runewidth.Truncate(name, maxContainerNameWidth-lipgloss.Width(exitCode)-lipgloss.Width(pin), "...")
+		name += exitCode
 		if _, ok := m.selected[i]; ok {
 			check = checkStyle.Render("✔")
 		}
@@ -353,38 +552,100 @@ func buildContainerView(m model) (string, string) {
 
 	// pad body height
 	padBodyHeight(&bodyL, len(m.containers)+2)
-	return bodyLStyle.Render(bodyL), bodyRStyle.Render(bodyR)
+	return bodyLStyle.Render(bodyL), bodyRStyle.Render(scrollLines(bodyR, m.descOffset))
 }
 
-// ----------------------------- main view -----------------------------
-
-func (m model) View() string {
-	var final string
-	var bodyL, bodyR, body, bottom string
+// buildCursorDesc build the detail pane of the item under cursor
+func buildCursorDesc(m model) string {
+	if m.cursor < 0 || m.cursor >= getCurrentViewItemCount(m) {
+		return ""
+	}
 
-	// body L
+	var desc string
 	switch m.page {
 	case pageContainer:
-		bodyL, bodyR = buildContainerView(m)
+		c := m.containers[m.cursor]
+		desc = buildContainerDescShort(c.id)
+		if c.pod != "" {
+			desc += fmt.Sprintf("Pod     : %s\n", c.pod)
+		}
+		if stats, ok := m.stats[c.id]; ok {
+			desc += fmt.Sprintf("Stats   : %s\n", formatStats(stats))
+		}
+		if c.size > 0 {
+			desc += fmt.Sprintf("Size    : %s\n", convertSizeToHumanRedable(c.size))
+		}
 	case pageImage:
-		bodyL, bodyR = buildImageView(m)
+		desc = buildImageDescShort(m.images[m.cursor])
 	case pageVolume:
-		bodyL, bodyR = buildVolumeView(m)
+		desc = buildVolumeDescShort(m.volumes[m.cursor])
+	case pageLog:
+		desc = buildHistoryDesc(historyAt(m, m.cursor))
+	case pageSystem:
+		desc = buildSystemDesc(m)
 	}
+	return desc
+}
+
+// ----------------------------- main view -----------------------------
+
+func (m model) View() string {
+	var final string
+	var bodyL, bodyR, body, bottom string
 
 	//  title
 	title := buildTitleView(m)
 	title = titleStyle.Render(title)
 
-	// join left + right component
-	body = lipgloss.JoinHorizontal(lipgloss.Left, bodyL, bodyR)
-	body = bodyStyle.Render(body)
+	if m.palette != nil {
+		body = bodyStyle.Render(buildPaletteView(m))
+	} else if m.form != nil {
+		// form replace both left & right component, like drill-down
+		body = bodyStyle.Render(buildFormView(m))
+	} else if m.drill != drillNone {
+		// drill-down replace both left & right component
+		body = bodyStyle.Render(buildDrillView(m))
+	} else {
+		// body L
+		switch m.page {
+		case pageContainer:
+			bodyL, bodyR = buildContainerView(m)
+		case pageImage:
+			bodyL, bodyR = buildImageView(m)
+		case pageVolume:
+			bodyL, bodyR = buildVolumeView(m)
+		case pageLog:
+			bodyL, bodyR = buildHistoryView(m)
+		case pageSystem:
+			bodyL, bodyR = buildSystemView(m)
+		}
+
+		// join left + right component
+		body = lipgloss.JoinHorizontal(lipgloss.Left, bodyL, bodyR)
+		body = bodyStyle.Render(body)
+	}
 
 	// bottom
 	bottom = buildLogView(m)
+	if status := buildStatusView(m); status != "" {
+		bottom = lipgloss.JoinVertical(lipgloss.Left, status, bottom)
+	}
+	if t := buildToastView(m); t != "" {
+		bottom = lipgloss.JoinVertical(lipgloss.Left, t, bottom)
+	}
+	if m.prompt != nil {
+		bottom = lipgloss.JoinVertical(lipgloss.Left, buildPromptView(m), bottom)
+	}
 
 	// help
 	help := m.help.View(m.keys)
+	if m.palette != nil {
+		help = m.help.View(paletteHelp())
+	} else if m.form != nil {
+		help = m.help.View(formHelp(m))
+	} else if m.drill != drillNone {
+		help = m.help.View(drillHelp(m))
+	}
 	padOuterComponent(&help, m.width)
 
 	// join title + body + log + help
@@ -392,10 +653,18 @@ func (m model) View() string {
 	appStyle.MarginLeft((m.width - fullWidth) / 2)
 
 	// 0 containers/ image
+	if m.drill != drillNone || m.form != nil || m.palette != nil {
+		return title + "\n" + appStyle.Render(final) + "\n" + help
+	}
 	if len(m.containers) == 0 && m.page == pageContainer {
+		if m.pinnedOnly {
+			return buildEmptyBody("\nNo pinned containers, press shift+f to show all.", title, m.width)
+		}
 		return buildEmptyBody("\nNo containers found.", title, m.width)
 	} else if len(m.images) == 0 && m.page == pageImage {
 		return buildEmptyBody("\nNo images found.", title, m.width)
+	} else if len(m.history) == 0 && m.page == pageLog {
+		return buildEmptyBody("\nNo actions yet.", title, m.width)
 	}
 
 	return title + "\n" + appStyle.Render(final) + "\n" + help
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	docker "github.com/fsouza/go-dockerclient"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	toastDuration     = 8 * time.Second
+	notifyLogLines    = 5
+	defaultNotifyMode = "bell"
+)
+
+// watchRule watch containers by name glob (e.g. "api-*") and/or label
+// ("key" or "key=value"), both must match when set
+type watchRule struct {
+	Name  string `json:"name,omitempty"`
+	Label string `json:"label,omitempty"`
+}
+
+func (r watchRule) matches(name string, labels map[string]string) bool {
+	if r.Name == "" && r.Label == "" {
+		return false
+	}
+	if r.Name != "" {
+		if ok, _ := path.Match(r.Name, name); !ok {
+			return false
+		}
+	}
+	if r.Label != "" {
+		k, v, hasValue := strings.Cut(r.Label, "=")
+		value, ok := labels[k]
+		if !ok || (hasValue && value != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// toast is a short lived message shown above the logs
+type toast struct {
+	title string
+	lines []string
+	until time.Time
+}
+
+// DockerEventMsg is a container event from the daemon event stream
+type DockerEventMsg struct {
+	event *docker.APIEvents
+}
+
+// NotifyMsg is a state change of a watched container, ready to be notified
+type NotifyMsg struct {
+	name     string
+	event    string // e.g. "exited with code 1"
+	logLines []string
+}
+
+// isWatched tell if a container is watched, toggling with W override the
+// rules of the config
+func (m model) isWatched(name string, labels map[string]string) bool {
+	if watched, ok := m.watched[name]; ok {
+		return watched
+	}
+	for _, rule := range m.config.Watch {
+		if rule.matches(name, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// subscribeEvents start listening to the daemon events, go-dockerclient
+// reconnect by itself when the stream break
+func subscribeEvents(client *docker.Client) (chan *docker.APIEvents, error) {
+	ch := make(chan *docker.APIEvents, 64)
+	if err := client.AddEventListener(ch); err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// listenEvents wait for the next event
+func listenEvents(ch chan *docker.APIEvents) tea.Cmd {
+	if ch == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		event, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return DockerEventMsg{event: event}
+	}
+}
+
+// watchedEvent return the notification of a container event, empty if
+// the event isn't worth one
+func watchedEvent(e *docker.APIEvents) string {
+	if e.Type != "container" {
+		return ""
+	}
+	switch e.Action {
+	case "die":
+		return "exited with code " + e.Actor.Attributes["exitCode"]
+	case "restart":
+		return "restarted"
+	case "health_status: unhealthy":
+		return "is unhealthy"
+	}
+	// "oom" is followed by "die", which tell whether it was OOM killed
+	return ""
+}
+
+func handleDockerEvent(m model, msg DockerEventMsg) (tea.Model, tea.Cmd) {
+	listen := listenEvents(m.events)
+	e := msg.event
+	event := watchedEvent(e)
+	name := e.Actor.Attributes["name"]
+	// attributes of container events carry its labels too
+	if event == "" || !m.isWatched(name, e.Actor.Attributes) {
+		return m, listen
+	}
+	return m, tea.Batch(listen, fetchNotification(e.Actor.ID, name, event))
+}
+
+// fetchNotification complete an event with the last log lines
+func fetchNotification(id, name, event string) tea.Cmd {
+	return func() tea.Msg {
+		msg := NotifyMsg{name: name, event: event}
+		client, err := newClient()
+		if err != nil {
+			return msg
+		}
+		ctr, err := client.InspectContainerWithOptions(docker.InspectContainerOptions{ID: id})
+		if err != nil {
+			return msg
+		}
+		if ctr.State.OOMKilled && strings.HasPrefix(event, "exited") {
+			msg.event = "was OOM killed, " + event
+		}
+		if ctr.State.Restarting {
+			msg.event += ", restarting"
+		}
+
+		var out bytes.Buffer
+		err = client.Logs(docker.LogsOptions{
+			Container:    id,
+			OutputStream: &out,
+			ErrorStream:  &out,
+			Stdout:       true,
+			Stderr:       true,
+			Tail:         fmt.Sprint(notifyLogLines),
+			RawTerminal:  ctr.Config != nil && ctr.Config.Tty,
+		})
+		if err == nil {
+			for _, line := range strings.Split(strings.TrimRight(out.String(), "\n"), "\n") {
+				if line = printable(line); line != "" {
+					msg.logLines = append(msg.logLines, line)
+				}
+			}
+		}
+		return msg
+	}
+}
+
+func (m *model) notify(msg NotifyMsg) {
+	title := printable(fmt.Sprintf("%s %s", msg.name, msg.event))
+	m.toast = &toast{title: title, lines: msg.logLines, until: time.Now().Add(toastDuration)}
+	m.logs += fmt.Sprintf("🔔 %s\n", title)
+	terminalNotify(m.config.Notify, "killer-whale", title)
+}
+
+// terminalNotify ring the bell or send an OSC 9 (iTerm2, WezTerm, ...) or
+// OSC 777 (urxvt, foot, ...) desktop notification
+func terminalNotify(mode, title, body string) {
+	if mode == "" {
+		mode = defaultNotifyMode
+	}
+	var seq string
+	switch mode {
+	case "bell":
+		seq = "\a"
+	case "osc9":
+		seq = "\x1b]9;" + title + ": " + body + "\a"
+	case "osc777":
+		seq = "\x1b]777;notify;" + title + ";" + body + "\a"
+	default: // none
+		return
+	}
+	if os.Getenv("TMUX") != "" && mode != "bell" {
+		// tmux passthrough, escapes are doubled
+		seq = "\x1bPtmux;" + strings.ReplaceAll(seq, "\x1b", "\x1b\x1b") + "\x1b\\"
+	}
+	// stdout belong to the renderer, the terminal is the same
+	os.Stderr.WriteString(seq)
+}
+
+// toggleWatch watch/unwatch the selected containers, or the one under cursor
+func toggleWatch(m model) (tea.Model, tea.Cmd) {
+	targets := []Container{}
+	for i := range m.selected {
+		targets = append(targets, m.containers[i])
+	}
+	if len(targets) == 0 {
+		targets = append(targets, m.containers[m.cursor])
+	}
+
+	// watch all unless all are already watched
+	watch := false
+	for _, c := range targets {
+		if !m.isWatched(c.name, c.labels) {
+			watch = true
+		}
+	}
+	if m.watched == nil {
+		m.watched = make(map[string]bool)
+	}
+	for _, c := range targets {
+		m.watched[c.name] = watch
+	}
+
+	action := "Watching"
+	if !watch {
+		action = "Stopped watching"
+	}
+	m.logs = fmt.Sprintf("👀 %s %s container(s)\n", action, itemCountStyle.Render(fmt.Sprint(len(targets))))
+	return m, nil
+}
+
+func buildToastView(m model) string {
+	t := m.toast
+	if t == nil || time.Now().After(t.until) {
+		return ""
+	}
+	s := toastTitleStyle.Render("🔔 " + t.title)
+	for _, line := range t.lines {
+		s += "\n" + toastLineStyle.Render(runewidth.Truncate(line, fullWidth-8, "…"))
+	}
+	return toastStyle.Render(s)
+}